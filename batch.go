@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// parallelSeparator separates the tasks of `mise run a ::: b`, which runs them in parallel.
+const parallelSeparator = ":::"
+
+// Checkboxes of the tasks table's mark column.
+const (
+	markedBox   = "[x]"
+	unmarkedBox = "[ ]"
+)
+
+// failureLinePattern matches the line mise prints when a task of a parallel
+// run fails, naming the task in its output prefix.
+var failureLinePattern = regexp.MustCompile(`^\[(.+)\] ERROR task failed$`)
+
+// batchMode is how the tasks of a batch run.
+type batchMode int
+
+const (
+	batchStopOnFailure batchMode = iota // one after another, stopping at the first failure
+	batchKeepGoing                      // one after another, running the rest after a failure
+	batchParallel                       // all at once in a single mise run
+)
+
+// String returns how the mode runs tasks, for the output view header.
+func (b batchMode) String() string {
+	switch b {
+	case batchStopOnFailure:
+		return "in sequence"
+	case batchKeepGoing:
+		return "in sequence, keep going"
+	case batchParallel:
+		return "in parallel"
+	}
+	return "unknown"
+}
+
+// batchStatus is how far a task of a batch got.
+type batchStatus int
+
+const (
+	batchPending batchStatus = iota
+	batchRunning
+	batchSucceeded
+	batchFailed
+	batchSkipped
+	batchUnknown // the run failed, but whether this task did can't be told
+)
+
+// batchTask is a task of a batch and how far it got.
+type batchTask struct {
+	name   string
+	status batchStatus
+}
+
+// taskBatch runs the tasks marked in the tasks table in the output view.
+type taskBatch struct {
+	mode    batchMode
+	tasks   []batchTask
+	current int // index of the running task when they run one after another
+}
+
+// active reports whether the output view shows a batch.
+func (b taskBatch) active() bool {
+	return len(b.tasks) > 0
+}
+
+// taskCommand returns the mise arguments that run tasks with args. Several
+// tasks run in parallel and without args. withStdin runs a single task in raw
+// mode, where mise connects stdin to it. Raw mode runs dependencies one at a
+// time and turns off mise's redaction of secrets, so only runs that ask for
+// input use it.
+func taskCommand(tasks, args []string, withStdin bool) []string {
+	if len(tasks) > 1 {
+		cmdArgs := []string{"run"}
+		for i, task := range tasks {
+			if i > 0 {
+				cmdArgs = append(cmdArgs, parallelSeparator)
+			}
+			cmdArgs = append(cmdArgs, task)
+		}
+		return cmdArgs
+	}
+
+	cmdArgs := []string{"run"}
+	if withStdin {
+		cmdArgs = append(cmdArgs, "--raw")
+	}
+	cmdArgs = append(cmdArgs, tasks[0])
+	// If there are arguments, add -- separator so mise passes them to the task
+	if len(args) > 0 {
+		cmdArgs = append(cmdArgs, "--")
+		cmdArgs = append(cmdArgs, args...)
+	}
+	return cmdArgs
+}
+
+// toggleMark marks the selected task for a batch or unmarks it, then moves
+// to the next task so several can be marked in a row.
+func (m model) toggleMark() model {
+	task, ok := m.selectedTask()
+	if !ok {
+		return m
+	}
+	if i := slices.Index(m.markedTasks, task.Name); i >= 0 {
+		m.markedTasks = slices.Delete(slices.Clone(m.markedTasks), i, i+1)
+	} else {
+		m.markedTasks = append(slices.Clone(m.markedTasks), task.Name)
+	}
+	m.tasksTable.SetRows(m.taskRows(m.filteredTasks))
+	m.tasksTable.MoveDown(1)
+	return m
+}
+
+// markedTaskNames returns the marked tasks that still exist, in the order they were marked.
+func (m model) markedTaskNames() []string {
+	var names []string
+	for _, name := range m.markedTasks {
+		if slices.ContainsFunc(m.tasks, func(t loader.Task) bool { return t.Name == name }) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// openBatchPrompt asks how to run the marked tasks.
+func (m model) openBatchPrompt() model {
+	if len(m.markedTaskNames()) == 0 {
+		return m
+	}
+	m.batchPromptActive = true
+	return m
+}
+
+// handleBatchPrompt handles key presses while the batch mode is chosen.
+func (m model) handleBatchPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	modes := map[string]batchMode{"s": batchStopOnFailure, "c": batchKeepGoing, "p": batchParallel}
+	switch key := keyMsg.String(); key {
+	case "s", "c", "p":
+		m.batchPromptActive = false
+		tasks := make([]loader.Task, 0, len(m.markedTasks))
+		for _, name := range m.markedTaskNames() {
+			tasks = append(tasks, m.taskByName(name))
+		}
+		return m.guardRun(tasks, func(m model) (model, tea.Cmd) {
+			return m.startBatch(modes[key])
+		})
+	case keyEsc, "q":
+		m.batchPromptActive = false
+	}
+	return m, nil
+}
+
+// startBatch runs the marked tasks in the output view and clears the marks.
+func (m model) startBatch(mode batchMode) (model, tea.Cmd) {
+	names := m.markedTaskNames()
+	m.batchPromptActive = false
+	m.markedTasks = nil
+	m.tasksTable.SetRows(m.taskRows(m.filteredTasks))
+	if len(names) == 0 {
+		return m, nil
+	}
+	m.logger.Debug("running marked tasks", "tasks", names, "mode", mode.String())
+
+	m = m.resetOutputView()
+	m.batch = taskBatch{mode: mode, tasks: make([]batchTask, len(names))}
+	for i, name := range names {
+		m.batch.tasks[i] = batchTask{name: name}
+	}
+	if mode == batchParallel {
+		for i := range m.batch.tasks {
+			m.batch.tasks[i].status = batchRunning
+		}
+		return m.launchTasks(names, nil)
+	}
+	return m.runBatchTask(0)
+}
+
+// runBatchTask starts task i of a batch whose tasks run one after another.
+// Its output follows the output of the tasks before it.
+func (m model) runBatchTask(i int) (model, tea.Cmd) {
+	name := m.batch.tasks[i].name
+	m.batch.current = i
+	m.batch.tasks[i].status = batchRunning
+	m, cmd := m.launchTasks([]string{name}, nil)
+	m = m.handleTaskOutput(taskOutputMsg{run: m.runID, line: "▶ " + name, at: time.Now()})
+	return m, cmd
+}
+
+// continueBatch records how the run of a batch went and starts its next task.
+// A run that was cancelled stops the whole batch.
+func (m model) continueBatch(err error, cancelled bool) (model, tea.Cmd) {
+	tasks := slices.Clone(m.batch.tasks)
+	m.batch.tasks = tasks
+	if m.batch.mode == batchParallel {
+		m.batch.tasks = parallelResults(tasks, err, outputTexts(m.output))
+		return m, nil
+	}
+
+	tasks[m.batch.current].status = batchSucceeded
+	if err != nil {
+		tasks[m.batch.current].status = batchFailed
+	}
+	next := m.batch.current + 1
+	if next >= len(tasks) {
+		return m, nil
+	}
+	if cancelled || (err != nil && m.batch.mode == batchStopOnFailure) {
+		for i := next; i < len(tasks); i++ {
+			tasks[i].status = batchSkipped
+		}
+		return m, nil
+	}
+	return m.runBatchTask(next)
+}
+
+// parallelResults works out how the tasks of a parallel run went. mise
+// reports each task that fails with a line naming it, those tasks failed.
+// How the others went can't be told from a failed run: they may have
+// finished or been stopped when mise gave up.
+func parallelResults(tasks []batchTask, err error, output []string) []batchTask {
+	if err == nil {
+		for i := range tasks {
+			tasks[i].status = batchSucceeded
+		}
+		return tasks
+	}
+
+	var failed []string
+	for _, line := range output {
+		if match := failureLinePattern.FindStringSubmatch(strings.TrimSpace(ansi.Strip(line))); match != nil {
+			failed = append(failed, match[1])
+		}
+	}
+	for i := range tasks {
+		tasks[i].status = batchUnknown
+		if slices.Contains(failed, tasks[i].name) {
+			tasks[i].status = batchFailed
+		}
+	}
+	return tasks
+}
+
+// renderBatchStatus renders the tasks of the batch in the output view header.
+func (m model) renderBatchStatus() string {
+	if !m.batch.active() {
+		return ""
+	}
+	parts := []string{m.styles.help.Render(m.batch.mode.String() + ":")}
+	for _, task := range m.batch.tasks {
+		var part string
+		switch task.status {
+		case batchPending:
+			part = m.styles.help.Render("○ " + task.name)
+		case batchRunning:
+			part = m.styles.dimTitle.Render(m.taskSpinner.View() + " " + task.name)
+		case batchSucceeded:
+			part = m.styles.success.Render("✓ " + task.name)
+		case batchFailed:
+			part = m.styles.err.Render("✗ " + task.name)
+		case batchSkipped:
+			part = m.styles.help.Render("− " + task.name)
+		case batchUnknown:
+			part = m.styles.help.Render("? " + task.name)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderBatchPromptView renders the choice of how to run the marked tasks.
+func (m model) renderBatchPromptView() tea.View {
+	names := m.markedTaskNames()
+	title := m.styles.title.Render(fmt.Sprintf("Run %d marked tasks", len(names)))
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = m.styles.help.Render(fmt.Sprintf("%d. ", i+1)) + name
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, list...),
+		"",
+		m.argInputHelp.View(newBatchKeyMap()),
+	)
+
+	v := tea.NewView(content)
+	v.AltScreen = true
+	return v
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// batchStatuses returns the status of each task of the batch.
+func batchStatuses(b taskBatch) []batchStatus {
+	statuses := make([]batchStatus, len(b.tasks))
+	for i, task := range b.tasks {
+		statuses[i] = task.status
+	}
+	return statuses
+}
+
+func TestTaskCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		tasks     []string
+		args      []string
+		withStdin bool
+		want      []string
+	}{
+		{name: "single task", tasks: []string{"build"}, want: []string{"run", "build"}},
+		{
+			name:  "single task with args",
+			tasks: []string{"test"},
+			args:  []string{"-v"},
+			want:  []string{"run", "test", "--", "-v"},
+		},
+		{
+			name:      "single task with input",
+			tasks:     []string{"deploy"},
+			withStdin: true,
+			want:      []string{"run", "--raw", "deploy"},
+		},
+		{
+			name:  "parallel tasks",
+			tasks: []string{"lint", "test", "build"},
+			want:  []string{"run", "lint", ":::", "test", ":::", "build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskCommand(tt.tasks, tt.args, tt.withStdin); !slices.Equal(got, tt.want) {
+				t.Errorf("taskCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleMark(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"lint", "test", "build"}))
+
+	m = m.toggleMark()
+	if m.tasksTable.Cursor() != 1 {
+		t.Errorf("cursor = %d, want it on the next task", m.tasksTable.Cursor())
+	}
+	m.tasksTable.SetCursor(2)
+	m = m.toggleMark()
+	if got, want := m.markedTaskNames(), []string{"lint", "build"}; !slices.Equal(got, want) {
+		t.Fatalf("marked = %q, want %q", got, want)
+	}
+	if got := m.tasksTable.Rows()[0][0]; got != markedBox {
+		t.Errorf("mark column of a marked task = %q, want %q", got, markedBox)
+	}
+
+	m.tasksTable.SetCursor(0)
+	m = m.toggleMark()
+	if got, want := m.markedTaskNames(), []string{"build"}; !slices.Equal(got, want) {
+		t.Errorf("marked after unmarking = %q, want %q", got, want)
+	}
+	if got := m.tasksTable.Rows()[0][0]; got != unmarkedBox {
+		t.Errorf("mark column of an unmarked task = %q, want %q", got, unmarkedBox)
+	}
+}
+
+func TestBatch_InSequence(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    batchMode
+		want    []batchStatus
+		wantRun []string
+	}{
+		{
+			name:    "stops on failure",
+			mode:    batchStopOnFailure,
+			want:    []batchStatus{batchSucceeded, batchFailed, batchSkipped},
+			wantRun: []string{"lint", "test"},
+		},
+		{
+			name:    "keeps going",
+			mode:    batchKeepGoing,
+			want:    []batchStatus{batchSucceeded, batchFailed, batchSucceeded},
+			wantRun: []string{"lint", "test", "build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := createTestModel(nil, withTasks([]string{"build", "lint", "test"}, "lint", "test", "build"))
+			m, _ = m.startBatch(tt.mode)
+			if len(m.markedTasks) != 0 {
+				t.Error("marks were not cleared when the batch started")
+			}
+
+			results := map[string]error{"test": errors.New("exit status 1")}
+			var ran []string
+			for m.taskRunning {
+				ran = append(ran, m.runningTask)
+				m, _, _ = m.handleRunMsg(taskDoneMsg{run: m.runID, err: results[m.runningTask]})
+			}
+			if !slices.Equal(ran, tt.wantRun) {
+				t.Errorf("ran %q, want %q", ran, tt.wantRun)
+			}
+			if got := batchStatuses(m.batch); !slices.Equal(got, tt.want) {
+				t.Errorf("statuses = %v, want %v", got, tt.want)
+			}
+			if want := "▶ lint"; outputTexts(m.output)[0] != want {
+				t.Errorf("output starts with %q, want %q", outputTexts(m.output)[0], want)
+			}
+		})
+	}
+}
+
+func TestBatch_CancelStopsSequence(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"lint", "test"}, "lint", "test"))
+	m, _ = m.startBatch(batchKeepGoing)
+	m.taskStopping = true
+
+	m, _, _ = m.handleRunMsg(taskDoneMsg{run: m.runID, err: errors.New("signal: interrupt")})
+	if m.taskRunning {
+		t.Fatal("the next task started after the batch was cancelled")
+	}
+	if got, want := batchStatuses(m.batch), []batchStatus{batchFailed, batchSkipped}; !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestParallelResults(t *testing.T) {
+	newTasks := func() []batchTask {
+		return []batchTask{{name: "lint"}, {name: "test"}, {name: "build"}}
+	}
+	tests := []struct {
+		name   string
+		err    error
+		output []string
+		want   []batchStatus
+	}{
+		{
+			name: "all succeeded",
+			want: []batchStatus{batchSucceeded, batchSucceeded, batchSucceeded},
+		},
+		{
+			name: "mise names the failed task",
+			err:  errors.New("exit status 1"),
+			output: []string{
+				"[lint] no error found", "\x1b[31m[test]\x1b[0m ERROR task failed", "[build] compiling",
+			},
+			want: []batchStatus{batchUnknown, batchFailed, batchUnknown},
+		},
+		{
+			name:   "failure can't be told apart",
+			err:    errors.New("exit status 1"),
+			output: []string{"lint failed", "ERROR task failed"},
+			want:   []batchStatus{batchUnknown, batchUnknown, batchUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := batchStatuses(taskBatch{tasks: parallelResults(newTasks(), tt.err, tt.output)})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parallelResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate_RunMarkedInParallel(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"lint", "test", "build"}))
+
+	// Marking moves the cursor down, so this marks lint and test
+	m, _ = pressKeys(t, m, "space", "space", "R")
+	if !m.batchPromptActive || !slices.Equal(m.markedTasks, []string{"lint", "test"}) {
+		t.Fatalf("after marking and R prompt = %v, marked %q, want the mode asked for lint and test",
+			m.batchPromptActive, m.markedTasks)
+	}
+	m, cmd := pressKeys(t, m, "p")
+	if !m.taskRunning || m.batch.mode != batchParallel || cmd == nil {
+		t.Fatalf("after p running = %v in mode %v, want the marked tasks running in parallel", m.taskRunning, m.batch.mode)
+	}
+
+	for _, msg := range []tea.Msg{
+		taskOutputMsg{run: m.runID, line: "[test] ERROR task failed"},
+		taskDoneMsg{run: m.runID, err: errors.New("exit status 1")},
+	} {
+		next, _ := m.Update(msg)
+		m = next.(model)
+	}
+	if got, want := batchStatuses(m.batch), []batchStatus{batchUnknown, batchFailed}; !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want only the task mise named as failed", got)
+	}
+}
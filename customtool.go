@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// ErrInvalidToolSpec is returned when a custom tool isn't written as backend:tool.
+var ErrInvalidToolSpec = errors.New("custom tools are written as backend:tool, like npm:prettier")
+
+// toolSpecPattern matches a backend-qualified tool, like go:github.com/foo/bar or npm:prettier.
+var toolSpecPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*:[^\s@]+$`)
+
+// customToolItem is the picker entry to install a tool that isn't in the registry.
+type customToolItem struct{}
+
+// FilterValue implements list.Item.
+func (customToolItem) FilterValue() string { return "custom tool" }
+
+// Title implements list.DefaultItem.
+func (customToolItem) Title() string { return "Custom tool…" }
+
+// Description implements list.DefaultItem.
+func (customToolItem) Description() string {
+	return "any backend:tool, like go:github.com/foo/bar or npm:prettier"
+}
+
+// parseToolSpec trims spec and checks it names a backend and a tool.
+func parseToolSpec(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if !toolSpecPattern.MatchString(spec) {
+		return "", ErrInvalidToolSpec
+	}
+	return spec, nil
+}
+
+// openCustomTool asks for a backend-qualified tool to install.
+func (m model) openCustomTool() (model, tea.Cmd) {
+	m.logger.Debug("opening custom tool input")
+	m.pickerState = pickerEnterCustomTool
+	m.customTool = true
+	m.customToolErr = ""
+	return m, m.customToolInput.Focus()
+}
+
+// handleCustomToolKeys handles keys while a custom tool is typed. The tool is
+// valid once mise lists versions for it, which the version step loads anyway.
+func (m model) handleCustomToolKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc:
+		// Go back to tool selection
+		m.customTool = false
+		m.customToolInput.Blur()
+		m.pickerState = pickerSelectTool
+		return m, nil
+	case keyEnter:
+		spec, err := parseToolSpec(m.customToolInput.Value())
+		if err != nil {
+			m.customToolErr = err.Error()
+			return m, nil
+		}
+		m.customToolInput.Blur()
+		return m.loadPickerVersions(spec)
+	}
+
+	var cmd tea.Cmd
+	m.customToolInput, cmd = m.customToolInput.Update(msg)
+	m.customToolErr = ""
+	return m, cmd
+}
+
+// rejectCustomTool goes back to the custom tool input to show why its versions couldn't be loaded.
+func (m model) rejectCustomTool(reason string) model {
+	m.pickerState = pickerEnterCustomTool
+	m.customToolErr = reason
+	m.customToolInput.Focus()
+	return m
+}
+
+// renderCustomToolView renders the custom tool input.
+func (m model) renderCustomToolView() string {
+	var errLine string
+	if m.customToolErr != "" {
+		errLine = m.styles.err.Render(m.customToolErr)
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.styles.title.Render("Custom tool"),
+		"",
+		m.styles.help.Render("Backend and tool, like go:github.com/foo/bar or npm:prettier:"),
+		m.customToolInput.View(),
+		errLine,
+		m.argInputHelp.View(newCustomToolKeyMap()),
+	)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestParseToolSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "npm:prettier", want: "npm:prettier"},
+		{spec: "  go:github.com/foo/bar ", want: "go:github.com/foo/bar"},
+		{spec: "cargo:ripgrep", want: "cargo:ripgrep"},
+		{spec: "prettier", wantErr: true},
+		{spec: "npm:", wantErr: true},
+		{spec: "npm:prettier@3", wantErr: true},
+		{spec: "npm:pret tier", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseToolSpec(tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToolSpec) {
+					t.Errorf("parseToolSpec() error = %v, want ErrInvalidToolSpec", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseToolSpec() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomTool(t *testing.T) {
+	m := createTestModel(nil)
+	m, _, _ = m.openToolPicker()
+	m, _ = m.handleRegistryLoaded(loader.RegistryLoadedMsg{Tools: []loader.RegistryTool{{Name: "node"}}})
+	if _, ok := m.toolList.Items()[0].(customToolItem); !ok {
+		t.Fatal("the picker doesn't start with the custom tool entry")
+	}
+
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+	m, _ = m.handlePickerKeys(enter)
+	if m.pickerState != pickerEnterCustomTool {
+		t.Fatalf("pickerState = %v, want the custom tool input", m.pickerState)
+	}
+
+	m.customToolInput.SetValue("prettier")
+	m, _ = m.handlePickerKeys(enter)
+	if m.pickerState != pickerEnterCustomTool || m.customToolErr == "" {
+		t.Fatal("a tool without a backend was accepted")
+	}
+
+	m.customToolInput.SetValue("npm:prettier")
+	m, cmd := m.handlePickerKeys(enter)
+	if m.pickerState != pickerLoadingVersions || m.selectedTool != "npm:prettier" || cmd == nil {
+		t.Fatalf("pickerState = %v tool %q, want the versions of npm:prettier loading", m.pickerState, m.selectedTool)
+	}
+
+	// A tool mise can't list versions for goes back to the input
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "npm:prettier", Err: errors.New("not found")})
+	if m.pickerState != pickerEnterCustomTool || m.customToolErr != "not found" {
+		t.Fatalf("pickerState = %v err %q, want the input with the error", m.pickerState, m.customToolErr)
+	}
+
+	// Otherwise it continues to the versions, and Esc comes back to the input
+	m, _ = m.handlePickerKeys(enter)
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "npm:prettier", Versions: []string{"3.3.3"}})
+	if m.pickerState != pickerSelectVersion {
+		t.Fatalf("pickerState = %v, want the version list", m.pickerState)
+	}
+	m, _ = m.handlePickerKeys(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.pickerState != pickerEnterCustomTool || m.customToolInput.Value() != "npm:prettier" {
+		t.Errorf("pickerState = %v, want the input with the typed tool", m.pickerState)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/shlex"
+)
+
+// ErrInvalidEnvOverride is returned for an environment override that isn't KEY=VALUE.
+var ErrInvalidEnvOverride = errors.New("invalid environment override")
+
+// envNamePattern matches the environment variable names shells accept.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseEnvOverrides parses a list of KEY=VALUE pairs separated by spaces.
+// Values are quoted like shell words, so LOG_FORMAT="json pretty" is one
+// override. A variable set twice keeps the last value, like in a shell.
+func parseEnvOverrides(input string) ([]string, error) {
+	words, err := shlex.Split(input)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvOverride, err)
+	}
+	overrides := make([]string, 0, len(words))
+	for _, word := range words {
+		name, _, ok := strings.Cut(word, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w %q: expected KEY=VALUE", ErrInvalidEnvOverride, word)
+		}
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%w %q: %q is not a valid variable name", ErrInvalidEnvOverride, word, name)
+		}
+		overrides = append(overrides, word)
+	}
+	return overrides, nil
+}
+
+// maskedOverrides renders overrides for display with their values masked
+// like hidden environment variables, since they can hold secrets that mise
+// would redact from the output.
+func maskedOverrides(overrides []string) string {
+	masked := make([]string, len(overrides))
+	for i, override := range overrides {
+		name, value, _ := strings.Cut(override, "=")
+		masked[i] = name + "=" + maskValue(value)
+	}
+	return strings.Join(masked, " ")
+}
+
+// commandEnv returns the environment for a task command: prep's own
+// environment with the overrides applied, or nil to inherit it unchanged.
+// exec.Cmd keeps the last value of a variable that is set twice.
+func commandEnv(environ, overrides []string) []string {
+	if len(overrides) == 0 {
+		return nil
+	}
+	return append(append([]string{}, environ...), overrides...)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestParseEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", input: "  ", want: []string{}},
+		{name: "pairs", input: "LOG_LEVEL=debug CI=1", want: []string{"LOG_LEVEL=debug", "CI=1"}},
+		{name: "quoted value", input: `MSG="hello world" EMPTY=`, want: []string{"MSG=hello world", "EMPTY="}},
+		{name: "value with equals", input: "OPTS=a=b", want: []string{"OPTS=a=b"}},
+		{name: "missing equals", input: "CI", wantErr: true},
+		{name: "invalid name", input: "1X=2", wantErr: true},
+		{name: "empty name", input: "=value", wantErr: true},
+		{name: "unterminated quote", input: `MSG="hello`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEnvOverrides(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidEnvOverride) {
+					t.Fatalf("parseEnvOverrides(%q) error = %v, want ErrInvalidEnvOverride", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEnvOverrides(%q) error = %v", tt.input, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseEnvOverrides(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	environ := []string{"HOME=/home/me", "CI=0"}
+	if got := commandEnv(environ, nil); got != nil {
+		t.Errorf("commandEnv() without overrides = %q, want nil to inherit", got)
+	}
+
+	got := commandEnv(environ, []string{"CI=1"})
+	if want := []string{"HOME=/home/me", "CI=0", "CI=1"}; !slices.Equal(got, want) {
+		t.Errorf("commandEnv() = %q, want %q", got, want)
+	}
+	if len(environ) != 2 {
+		t.Error("commandEnv() modified the environment it was given")
+	}
+}
+
+func TestMaskedOverrides(t *testing.T) {
+	got := maskedOverrides([]string{"TOKEN=s3cret", "EMPTY=", "URL=https://a=b"})
+	if want := "TOKEN=●●●●●●●● EMPTY= URL=●●●●●●●●"; got != want {
+		t.Errorf("maskedOverrides() = %q, want %q", got, want)
+	}
+}
+
+func TestHandleArgInput_InvalidEnvKeepsDialogOpen(t *testing.T) {
+	m := createTestModel(nil)
+	m.argInputActive = true
+	m.argInputTask = "build"
+	m.envInput.SetValue("LOG_LEVEL")
+
+	next, cmd := m.handleArgInput(tea.KeyPressMsg{Code: tea.KeyEnter})
+	got, ok := next.(model)
+	if !ok {
+		t.Fatalf("handleArgInput() returned %T", next)
+	}
+	if cmd != nil || !got.argInputActive {
+		t.Fatal("the task should not run with invalid environment overrides")
+	}
+	if !errors.Is(got.argInputErr, ErrInvalidEnvOverride) || !got.envInputFocused {
+		t.Errorf("argInputErr = %v, envInputFocused = %v, want the error shown on the env field",
+			got.argInputErr, got.envInputFocused)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// outputFilePerm is the permission of saved output files.
+const outputFilePerm = 0o644
+
+// outputSavedMsg is sent when task output has been written to a file.
+type outputSavedMsg struct {
+	path  string
+	lines int
+	err   error
+}
+
+// outputCopiedMsg carries task output read for the clipboard.
+type outputCopiedMsg struct {
+	text  string
+	lines int
+	err   error
+}
+
+// writeOutput writes the spooled lines followed by the in-memory lines to w,
+// one per line, stripping ANSI escape codes if strip is set. It returns the
+// number of lines written.
+func writeOutput(w io.Writer, spooled io.Reader, lines []string, strip bool) (int, error) {
+	bw := bufio.NewWriter(w)
+	n := 0
+	writeLine := func(line string) error {
+		if strip {
+			line = ansi.Strip(line)
+		}
+		n++
+		_, err := bw.WriteString(line + "\n")
+		return err
+	}
+
+	br := bufio.NewReader(spooled)
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if writeErr := writeLine(strings.TrimSuffix(line, "\n")); writeErr != nil {
+				return n, writeErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("read spooled output: %w", err)
+		}
+	}
+
+	for _, line := range lines {
+		if err := writeLine(line); err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// defaultOutputPath suggests a file name for saving the output of task in
+// dir. A number is added to the name when the file already exists.
+func defaultOutputPath(dir, task string) string {
+	name := strings.NewReplacer(":", "-", "/", "-", " ", "-").Replace(task)
+	if name == "" {
+		name = "output"
+	}
+	path := filepath.Join(dir, name+".log")
+	for i := 2; fileExists(path); i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.log", name, i))
+	}
+	return path
+}
+
+// fileExists reports whether something exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// expandOutputPath resolves a path typed by the user: ~ is the home
+// directory and relative paths are relative to dir.
+func expandOutputPath(path, dir, homeDir string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(homeDir, rest)
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(dir, path)
+	}
+	return path
+}
+
+// saveOutput writes the full output to path. It refuses to replace an
+// existing file. It closes spooled when done, so the spool outlives the model
+// closing it.
+func saveOutput(path string, spooled io.ReadCloser, lines []string, strip bool) tea.Cmd {
+	return func() tea.Msg {
+		defer spooled.Close()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, outputFilePerm)
+		if errors.Is(err, fs.ErrExist) {
+			return outputSavedMsg{path: path, err: fmt.Errorf("%s already exists, choose another name", path)}
+		}
+		if err != nil {
+			return outputSavedMsg{path: path, err: fmt.Errorf("create %s: %w", path, err)}
+		}
+		n, writeErr := writeOutput(f, spooled, lines, strip)
+		closeErr := f.Close()
+		if err = errors.Join(writeErr, closeErr); err != nil {
+			return outputSavedMsg{path: path, err: fmt.Errorf("write %s: %w", path, err)}
+		}
+		return outputSavedMsg{path: path, lines: n}
+	}
+}
+
+// copyOutput reads the full output for the clipboard. It closes spooled when done.
+func copyOutput(spooled io.ReadCloser, lines []string, strip bool) tea.Cmd {
+	return func() tea.Msg {
+		defer spooled.Close()
+		var b strings.Builder
+		n, err := writeOutput(&b, spooled, lines, strip)
+		if err != nil {
+			return outputCopiedMsg{err: err}
+		}
+		return outputCopiedMsg{text: b.String(), lines: n}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rshep3087/prep/internal/spool"
+)
+
+func TestWriteOutput(t *testing.T) {
+	spooled := "\x1b[32mok\x1b[0m first\nsecond\n"
+	lines := []string{"third", "\x1b[31mFAIL\x1b[0m fourth"}
+
+	tests := []struct {
+		name  string
+		strip bool
+		want  string
+	}{
+		{
+			name:  "strips ANSI codes",
+			strip: true,
+			want:  "ok first\nsecond\nthird\nFAIL fourth\n",
+		},
+		{
+			name:  "keeps ANSI codes",
+			strip: false,
+			want:  spooled + "third\n\x1b[31mFAIL\x1b[0m fourth\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			n, err := writeOutput(&b, strings.NewReader(spooled), lines, tt.strip)
+			if err != nil {
+				t.Fatalf("writeOutput() error = %v", err)
+			}
+			if n != 4 {
+				t.Errorf("writeOutput() wrote %d lines, want 4", n)
+			}
+			if b.String() != tt.want {
+				t.Errorf("writeOutput() = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveOutput_IncludesSpooledLines(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	s, err := spool.New()
+	if err != nil {
+		t.Fatalf("spool.New() error = %v", err)
+	}
+	defer spool.Close(s)
+	if err := s.Write("dropped 1", "dropped 2"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	path := filepath.Join(dir, "build.log")
+	msg, ok := saveOutput(path, s.Snapshot(), []string{"kept"}, true)().(outputSavedMsg)
+	if !ok {
+		t.Fatal("saveOutput did not return an outputSavedMsg")
+	}
+	if msg.err != nil || msg.lines != 3 {
+		t.Fatalf("saveOutput() = %d lines, %v, want 3 lines", msg.lines, msg.err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading saved output: %v", err)
+	}
+	if string(got) != "dropped 1\ndropped 2\nkept\n" {
+		t.Errorf("saved output = %q", got)
+	}
+
+	// An existing file is never replaced
+	msg, _ = saveOutput(path, s.Snapshot(), []string{"again"}, true)().(outputSavedMsg)
+	if msg.err == nil {
+		t.Error("saveOutput() replaced an existing file")
+	}
+	if got, _ = os.ReadFile(path); string(got) != "dropped 1\ndropped 2\nkept\n" {
+		t.Errorf("saved output = %q after saving again, want it unchanged", got)
+	}
+}
+
+func TestOutputPaths(t *testing.T) {
+	if got := defaultOutputPath("/p", "test:unit"); got != "/p/test-unit.log" {
+		t.Errorf("defaultOutputPath() = %q, want %q", got, "/p/test-unit.log")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"build.log", "build-2.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := defaultOutputPath(dir, "build"), filepath.Join(dir, "build-3.log"); got != want {
+		t.Errorf("defaultOutputPath() = %q with build.log taken, want %q", got, want)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "out.log", want: "/p/out.log"},
+		{path: "~/logs/out.log", want: "/home/u/logs/out.log"},
+		{path: "/tmp/out.log", want: "/tmp/out.log"},
+	}
+	for _, tt := range tests {
+		if got := expandOutputPath(tt.path, "/p", "/home/u"); got != tt.want {
+			t.Errorf("expandOutputPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/textinput"
+	"charm.land/lipgloss/v2"
+	"github.com/sahilm/fuzzy"
+
+	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/state"
+)
+
+// filterCharLimit is the maximum length of a filter query.
+const filterCharLimit = 100
+
+// sourceFilterPrefix marks a filter term that matches against the task's source file.
+const sourceFilterPrefix = "src:"
+
+// Ranking bonuses added to fuzzy scores so name matches beat alias matches,
+// which beat description matches.
+const (
+	nameMatchBonus  = 200
+	aliasMatchBonus = 150
+)
+
+// Frecency weights per run by age, and the cap on the boost it adds to a match.
+// The cap only bounds the boost: a frequently run task can still outrank a
+// match in a better field when its fuzzy score is within the cap.
+const (
+	frecencyWeightDay    = 100 // run within the last day
+	frecencyWeightWeek   = 70  // run within the last week
+	frecencyWeightMonth  = 50  // run within the last month
+	frecencyWeightOlder  = 20  // anything older
+	frecencyBoostDivisor = 10  // scales summed weights down to a score boost
+	maxFrecencyBoost     = 40  // below the gap between field bonuses, so it only nudges close matches
+)
+
+// Age buckets for frecency weights.
+const (
+	frecencyDay   = 24 * time.Hour
+	frecencyWeek  = 7 * frecencyDay
+	frecencyMonth = 30 * frecencyDay
+)
+
+// tableFilter is the "/" filter of one table.
+type tableFilter struct {
+	input   textinput.Model
+	editing bool // whether the input has focus and receives key presses
+}
+
+// newTableFilter creates a tableFilter with the given placeholder.
+func newTableFilter(placeholder string) tableFilter {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.CharLimit = filterCharLimit
+	input.SetWidth(defaultInputWidth)
+	return tableFilter{input: input}
+}
+
+// applied reports whether the filter narrows its table, either while typing
+// or because a query was kept with Enter.
+func (f tableFilter) applied() bool {
+	return f.editing || f.input.Value() != ""
+}
+
+// rowMatch is an item that matched a filter, with the matched byte offsets
+// in each of its fields for highlighting.
+type rowMatch struct {
+	index   int     // index of the item in the slice that was matched
+	score   int     // best field score including its bonus
+	offsets [][]int // offsets per field, nil for fields that did not match
+}
+
+// matchFields fuzzy matches pattern against every field of every item.
+// columns holds one value per item for each field, and bonuses the score
+// added to a match in that field. Matches are returned in item order.
+func matchFields(pattern string, columns [][]string, bonuses []int) []rowMatch {
+	if len(columns) == 0 {
+		return nil
+	}
+
+	// Score each field separately so they can be weighted and highlighted independently
+	byIndex := make(map[int]*rowMatch)
+	for field, values := range columns {
+		for _, f := range fuzzy.FindNoSort(pattern, values) {
+			score := f.Score + bonuses[field]
+			match, ok := byIndex[f.Index]
+			if !ok {
+				match = &rowMatch{index: f.Index, score: score, offsets: make([][]int, len(columns))}
+				byIndex[f.Index] = match
+			}
+			match.score = max(match.score, score)
+			match.offsets[field] = f.MatchedIndexes
+		}
+	}
+
+	matches := make([]rowMatch, 0, len(byIndex))
+	for i := range columns[0] {
+		if match, ok := byIndex[i]; ok {
+			matches = append(matches, *match)
+		}
+	}
+	return matches
+}
+
+// sortMatches orders matches best first. It is stable so equal scores keep
+// the table's existing order.
+func sortMatches(matches []rowMatch) {
+	slices.SortStableFunc(matches, func(a, b rowMatch) int {
+		return b.score - a.score
+	})
+}
+
+// taskQuery is a parsed filter query.
+type taskQuery struct {
+	pattern string   // fuzzy pattern matched against name, aliases and description
+	sources []string // lowercase substrings the task's source path must contain
+}
+
+// parseTaskQuery splits a filter query into its fuzzy pattern and operator terms.
+// For example "src:backend test" keeps tasks defined under backend and fuzzy matches "test".
+func parseTaskQuery(query string) taskQuery {
+	var q taskQuery
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if src, ok := strings.CutPrefix(field, sourceFilterPrefix); ok {
+			if src != "" {
+				q.sources = append(q.sources, strings.ToLower(src))
+			}
+			continue
+		}
+		terms = append(terms, field)
+	}
+	q.pattern = strings.Join(terms, " ")
+	return q
+}
+
+// matchesSource reports whether the task's source path contains every source term.
+func (q taskQuery) matchesSource(task loader.Task) bool {
+	source := strings.ToLower(task.Source)
+	for _, s := range q.sources {
+		if !strings.Contains(source, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// taskMatch is a task that matched the filter, with the matched byte offsets
+// in each column for highlighting.
+type taskMatch struct {
+	task    loader.Task
+	score   int
+	name    []int // offsets into task.Name
+	aliases []int // offsets into the comma-joined aliases
+	desc    []int // offsets into task.Description
+}
+
+// filterTasks returns the tasks matching query, best matches first.
+// frecency boosts tasks that were run often and recently, see taskFrecency.
+func filterTasks(tasks []loader.Task, query string, frecency map[string]int) []taskMatch {
+	q := parseTaskQuery(query)
+
+	candidates := make([]loader.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if q.matchesSource(task) {
+			candidates = append(candidates, task)
+		}
+	}
+
+	// Operators alone narrow the list without reordering it
+	if q.pattern == "" {
+		matches := make([]taskMatch, len(candidates))
+		for i, task := range candidates {
+			matches[i] = taskMatch{task: task}
+		}
+		return matches
+	}
+
+	names := make([]string, len(candidates))
+	aliases := make([]string, len(candidates))
+	descs := make([]string, len(candidates))
+	for i, task := range candidates {
+		names[i] = task.Name
+		aliases[i] = strings.Join(task.Aliases, ", ")
+		descs[i] = task.Description
+	}
+
+	found := matchFields(q.pattern, [][]string{names, aliases, descs}, []int{nameMatchBonus, aliasMatchBonus, 0})
+	for i := range found {
+		found[i].score += min(frecency[candidates[found[i].index].Name]/frecencyBoostDivisor, maxFrecencyBoost)
+	}
+	// Equal scores keep the table's existing order (favorites, sort mode)
+	sortMatches(found)
+
+	matches := make([]taskMatch, len(found))
+	for i, f := range found {
+		matches[i] = taskMatch{
+			task:    candidates[f.index],
+			score:   f.score,
+			name:    f.offsets[0],
+			aliases: f.offsets[1],
+			desc:    f.offsets[2],
+		}
+	}
+	return matches
+}
+
+// toolMatchKey identifies a tool row in filter matches. The same tool can be
+// listed once per active version.
+func toolMatchKey(tool loader.Tool) string {
+	return tool.Name + "@" + tool.Version
+}
+
+// filterTools returns the tools matching query on name, version, requested
+// version or source, best matches first, along with their matches keyed by
+// toolMatchKey. An empty query returns every tool.
+func filterTools(tools []loader.Tool, query string) ([]loader.Tool, map[string]rowMatch) {
+	pattern := strings.TrimSpace(query)
+	if pattern == "" {
+		return tools, nil
+	}
+
+	columns := make([][]string, 4) //nolint:mnd // one per tools table column
+	for _, tool := range tools {
+		columns[0] = append(columns[0], tool.Name)
+		columns[1] = append(columns[1], tool.Version)
+		columns[2] = append(columns[2], tool.RequestedVersion)
+		columns[3] = append(columns[3], formatSourcePath(tool.SourcePath))
+	}
+	found := matchFields(pattern, columns, []int{nameMatchBonus, 0, 0, 0})
+	sortMatches(found)
+
+	filtered := make([]loader.Tool, len(found))
+	matches := make(map[string]rowMatch, len(found))
+	for i, f := range found {
+		filtered[i] = tools[f.index]
+		matches[toolMatchKey(tools[f.index])] = f
+	}
+	return filtered, matches
+}
+
+// filterEnvVars returns the env vars matching query on name, or on value for
+// unmasked vars, best matches first, along with their matches keyed by name.
+// Masked values are never searched so filtering cannot reveal secrets.
+// An empty query returns every env var.
+func filterEnvVars(envVars []loader.EnvVar, query string) ([]loader.EnvVar, map[string]rowMatch) {
+	pattern := strings.TrimSpace(query)
+	if pattern == "" {
+		return envVars, nil
+	}
+
+	names := make([]string, len(envVars))
+	values := make([]string, len(envVars))
+	for i, ev := range envVars {
+		names[i] = ev.Name
+		if !ev.Masked {
+			values[i] = ev.Value
+		}
+	}
+	found := matchFields(pattern, [][]string{names, values}, []int{nameMatchBonus, 0})
+	sortMatches(found)
+
+	filtered := make([]loader.EnvVar, len(found))
+	matches := make(map[string]rowMatch, len(found))
+	for i, f := range found {
+		filtered[i] = envVars[f.index]
+		matches[envVars[f.index].Name] = f
+	}
+	return filtered, matches
+}
+
+// taskFrecency sums age-weighted runs per task. Recent runs count for more than old ones.
+func taskFrecency(runs []state.Run, now time.Time) map[string]int {
+	frecency := make(map[string]int)
+	for _, run := range runs {
+		age := now.Sub(run.StartedAt)
+		switch {
+		case age < frecencyDay:
+			frecency[run.Task] += frecencyWeightDay
+		case age < frecencyWeek:
+			frecency[run.Task] += frecencyWeightWeek
+		case age < frecencyMonth:
+			frecency[run.Task] += frecencyWeightMonth
+		default:
+			frecency[run.Task] += frecencyWeightOlder
+		}
+	}
+	return frecency
+}
+
+// highlightRunes renders s with the runes at the given byte offsets in hl
+// and everything else in base. Consecutive runes share one styled segment
+// to keep escape sequences short.
+func highlightRunes(s string, offsets []int, base, hl lipgloss.Style) string {
+	var b strings.Builder
+	var segment strings.Builder
+	segmentMatched := false
+
+	flush := func() {
+		if segment.Len() == 0 {
+			return
+		}
+		if segmentMatched {
+			b.WriteString(hl.Render(segment.String()))
+		} else {
+			b.WriteString(base.Render(segment.String()))
+		}
+		segment.Reset()
+	}
+
+	for i, r := range s {
+		matched := slices.Contains(offsets, i)
+		if matched != segmentMatched {
+			flush()
+			segmentMatched = matched
+		}
+		segment.WriteRune(r)
+	}
+	flush()
+	return b.String()
+}
+
+// shiftOffsets returns offsets moved right by n bytes.
+func shiftOffsets(offsets []int, n int) []int {
+	shifted := make([]int, len(offsets))
+	for i, o := range offsets {
+		shifted[i] = o + n
+	}
+	return shifted
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"charm.land/lipgloss/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/state"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantPattern string
+		wantSources []string
+	}{
+		{
+			name:        "plain pattern",
+			query:       "build",
+			wantPattern: "build",
+		},
+		{
+			name:        "source operator and pattern",
+			query:       "src:Backend test",
+			wantPattern: "test",
+			wantSources: []string{"backend"},
+		},
+		{
+			name:        "multiple operators",
+			query:       "src:api lint src:mise-tasks",
+			wantPattern: "lint",
+			wantSources: []string{"api", "mise-tasks"},
+		},
+		{
+			name:  "empty operator is ignored",
+			query: "src:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTaskQuery(tt.query)
+			if got.pattern != tt.wantPattern {
+				t.Errorf("pattern = %q, want %q", got.pattern, tt.wantPattern)
+			}
+			if !slices.Equal(got.sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", got.sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func matchNames(matches []taskMatch) []string {
+	names := make([]string, len(matches))
+	for i, match := range matches {
+		names[i] = match.task.Name
+	}
+	return names
+}
+
+func TestFilterTasks(t *testing.T) {
+	tasks := []loader.Task{
+		{Name: "build", Description: "Build the application binary", Source: "/p/mise.toml"},
+		{Name: "run", Aliases: []string{"gor"}, Description: "Run the application", Source: "/p/mise.toml"},
+		{Name: "test", Description: "Run all tests", Source: "/p/mise.toml"},
+		{Name: "lint", Description: "Run linter", Source: "/p/mise-tasks/lint"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		frecency map[string]int
+		want     []string
+	}{
+		{
+			name:  "matches aliases",
+			query: "gor",
+			want:  []string{"run"},
+		},
+		{
+			name:  "name matches rank above description matches",
+			query: "test",
+			want:  []string{"test"},
+		},
+		{
+			name:  "description-only matches are included",
+			query: "binary",
+			want:  []string{"build"},
+		},
+		{
+			name:  "source operator alone keeps order",
+			query: "src:mise.toml",
+			want:  []string{"build", "run", "test"},
+		},
+		{
+			name:  "source operator narrows fuzzy matches",
+			query: "src:mise-tasks run",
+			want:  []string{"lint"},
+		},
+		{
+			name:     "frecency lifts frequently run tasks among similar matches",
+			query:    "run",
+			frecency: map[string]int{"test": 400},
+			want:     []string{"run", "test", "lint"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchNames(filterTasks(tasks, tt.query, tt.frecency))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterTasks(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterTasks_HighlightOffsets(t *testing.T) {
+	tasks := []loader.Task{{Name: "release:version", Description: "Show the next version"}}
+
+	matches := filterTasks(tasks, "rv", nil)
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1", len(matches))
+	}
+	if want := []int{0, 8}; !slices.Equal(matches[0].name, want) {
+		t.Errorf("name offsets = %v, want %v", matches[0].name, want)
+	}
+}
+
+func TestTaskFrecency(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	runs := []state.Run{
+		{Task: "build", StartedAt: now.Add(-time.Hour)},
+		{Task: "build", StartedAt: now.Add(-3 * frecencyDay)},
+		{Task: "test", StartedAt: now.Add(-10 * frecencyDay)},
+		{Task: "test", StartedAt: now.Add(-60 * frecencyDay)},
+	}
+
+	got := taskFrecency(runs, now)
+	if want := frecencyWeightDay + frecencyWeightWeek; got["build"] != want {
+		t.Errorf("build frecency = %d, want %d", got["build"], want)
+	}
+	if want := frecencyWeightMonth + frecencyWeightOlder; got["test"] != want {
+		t.Errorf("test frecency = %d, want %d", got["test"], want)
+	}
+}
+
+func TestHighlightRunes(t *testing.T) {
+	hl := lipgloss.NewStyle().Bold(true)
+	got := highlightRunes("build", []int{0, 1, 4}, lipgloss.NewStyle(), hl)
+	want := hl.Render("bu") + "il" + hl.Render("d")
+	if got != want {
+		t.Errorf("highlightRunes() = %q, want %q", got, want)
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	tools := []loader.Tool{
+		{Name: "go", Version: "1.25.1", RequestedVersion: "1.25", SourcePath: "/p/mise.toml"},
+		{Name: "node", Version: "22.1.0", RequestedVersion: "lts", SourcePath: "/p/.tool-versions"},
+		{Name: "python", Version: "3.12.4", RequestedVersion: "3.12", SourcePath: "/p/mise.toml"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "empty query keeps every tool", query: "", want: []string{"go", "node", "python"}},
+		{name: "matches names", query: "pyt", want: []string{"python"}},
+		{name: "matches versions", query: "22.1", want: []string{"node"}},
+		{name: "matches requested versions", query: "lts", want: []string{"node"}},
+		{name: "matches sources", query: "tool-versions", want: []string{"node"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterTools(tools, tt.query)
+			names := make([]string, len(filtered))
+			for i, tool := range filtered {
+				names[i] = tool.Name
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("filterTools(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterEnvVars(t *testing.T) {
+	envVars := []loader.EnvVar{
+		{Name: "API_TOKEN", Value: "s3cret", Masked: true},
+		{Name: "DATABASE_URL", Value: "postgres://localhost", Masked: false},
+		{Name: "SECRET_URL", Value: "https://vault", Masked: true},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "matches names", query: "database", want: []string{"DATABASE_URL"}},
+		{name: "matches shown values", query: "postgres", want: []string{"DATABASE_URL"}},
+		{name: "ignores masked values", query: "vault", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, _ := filterEnvVars(envVars, tt.query)
+			names := make([]string, len(filtered))
+			for i, ev := range filtered {
+				names[i] = ev.Name
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("filterEnvVars(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
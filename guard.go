@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// defaultConfirmPatterns are the task names that need confirming unless -confirm says otherwise.
+const defaultConfirmPatterns = "release*,deploy:*,db:drop"
+
+// ErrInvalidConfirmPattern is returned for a -confirm pattern that isn't a valid glob.
+var ErrInvalidConfirmPattern = errors.New("invalid confirm pattern")
+
+// dangerousScriptPattern matches commands in run scripts that are hard to
+// undo: pushing to a remote, deleting recursively and applying infrastructure.
+var dangerousScriptPattern = regexp.MustCompile(
+	`\bgit\s+push\b|\brm\s+-[a-zA-Z]*([rR][a-zA-Z]*f|f[a-zA-Z]*[rR])|\bterraform\s+(apply|destroy)\b`,
+)
+
+// pendingConfirm is a run that waits for the name of a task to be typed.
+type pendingConfirm struct {
+	task    string                       // name to type
+	reasons []string                     // why the tasks need confirming
+	run     func(model) (model, tea.Cmd) // starts the run once confirmed
+}
+
+// active reports whether a run waits for confirmation.
+func (c pendingConfirm) active() bool {
+	return c.run != nil
+}
+
+// parseConfirmPatterns parses the comma separated task name globs of -confirm.
+func parseConfirmPatterns(s string) ([]string, error) {
+	var patterns []string
+	for p := range strings.SplitSeq(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidConfirmPattern, p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// taskScript returns what task runs: its run scripts, or the file of a file task.
+func taskScript(task loader.Task) string {
+	if len(task.Run) > 0 || task.Source == "" || strings.HasSuffix(task.Source, ".toml") {
+		return strings.Join(task.Run, "\n")
+	}
+	script, err := os.ReadFile(task.Source)
+	if err != nil {
+		return ""
+	}
+	return string(script)
+}
+
+// confirmReason returns why running task needs confirming: its name matches
+// one of patterns or its script does something hard to undo. It returns ""
+// for tasks that can run right away.
+func confirmReason(task loader.Task, patterns []string) string {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, task.Name); ok {
+			return fmt.Sprintf("%s matches %s", task.Name, p)
+		}
+	}
+	if match := dangerousScriptPattern.FindString(taskScript(task)); match != "" {
+		return fmt.Sprintf("%s runs %s", task.Name, match)
+	}
+	return ""
+}
+
+// taskByName returns the task called name. Tasks that aren't loaded only have their name.
+func (m model) taskByName(name string) loader.Task {
+	if i := slices.IndexFunc(m.tasks, func(t loader.Task) bool { return t.Name == name }); i >= 0 {
+		return m.tasks[i]
+	}
+	return loader.Task{Name: name}
+}
+
+// dependencies returns the tasks running task also runs, the tasks it
+// depends on and their dependencies in turn. Dependencies may be globs, like
+// lint:*, and pass args after the task name.
+func (m model) dependencies(task loader.Task) []loader.Task {
+	seen := map[string]bool{task.Name: true}
+	var deps []loader.Task
+	queue := []loader.Task{task}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, dep := range slices.Concat(next.Depends, next.DependsPost) {
+			for _, depTask := range m.dependencyTasks(dep) {
+				if seen[depTask.Name] {
+					continue
+				}
+				seen[depTask.Name] = true
+				deps = append(deps, depTask)
+				queue = append(queue, depTask)
+			}
+		}
+	}
+	return deps
+}
+
+// dependencyTasks returns the tasks a depends entry names.
+func (m model) dependencyTasks(dep string) []loader.Task {
+	fields := strings.Fields(dep)
+	if len(fields) == 0 {
+		return nil
+	}
+	var tasks []loader.Task
+	for _, task := range m.tasks {
+		if ok, _ := path.Match(fields[0], task.Name); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	if len(tasks) == 0 {
+		return []loader.Task{m.taskByName(fields[0])}
+	}
+	return tasks
+}
+
+// guardRun calls run to start tasks, unless one of them, or a task they
+// depend on, needs confirming. Then the name of the first one has to be
+// typed before they run.
+func (m model) guardRun(tasks []loader.Task, run func(model) (model, tea.Cmd)) (model, tea.Cmd) {
+	var confirm pendingConfirm
+	for _, task := range tasks {
+		if reason := confirmReason(task, m.confirmPatterns); reason != "" {
+			if confirm.task == "" {
+				confirm.task = task.Name
+			}
+			confirm.reasons = append(confirm.reasons, reason)
+		}
+		for _, dep := range m.dependencies(task) {
+			if reason := confirmReason(dep, m.confirmPatterns); reason != "" {
+				if confirm.task == "" {
+					confirm.task = task.Name
+				}
+				confirm.reasons = append(confirm.reasons, fmt.Sprintf("%s depends on %s: %s", task.Name, dep.Name, reason))
+			}
+		}
+	}
+	if confirm.task == "" {
+		return run(m)
+	}
+
+	m.logger.Debug("asking to confirm run", "task", confirm.task, "reasons", confirm.reasons)
+	confirm.run = run
+	m.confirm = confirm
+	m.confirmErr = ""
+	m.confirmInput.SetValue("")
+	m.confirmInput.Placeholder = confirm.task
+	m.confirmInput.Focus()
+	return m, nil
+}
+
+// handleConfirmInput handles input while a run waits for confirmation.
+func (m model) handleConfirmInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyPressMsg); ok {
+		switch keyMsg.String() {
+		case keyEsc:
+			m.logger.Debug("run not confirmed", "task", m.confirm.task)
+			m.confirm = pendingConfirm{}
+			m.confirmInput.Blur()
+			return m, nil
+		case keyEnter:
+			if m.confirmInput.Value() != m.confirm.task {
+				m.confirmErr = fmt.Sprintf("type %s to run it", m.confirm.task)
+				return m, nil
+			}
+			run := m.confirm.run
+			m.confirm = pendingConfirm{}
+			m.confirmInput.Blur()
+			return run(m)
+		}
+	}
+
+	var cmd tea.Cmd
+	m.confirmInput, cmd = m.confirmInput.Update(msg)
+	m.confirmErr = ""
+	return m, cmd
+}
+
+// renderConfirmView renders the confirmation of a run.
+func (m model) renderConfirmView() tea.View {
+	title := m.styles.err.Bold(true).Render("Run " + m.confirm.task + "?")
+	reasons := make([]string, len(m.confirm.reasons))
+	for i, reason := range m.confirm.reasons {
+		reasons[i] = m.styles.help.Render("• " + reason)
+	}
+
+	var errLine string
+	if m.confirmErr != "" {
+		errLine = m.styles.err.Render(m.confirmErr)
+	}
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, reasons...),
+		"",
+		m.styles.help.Render("Type the task name to confirm:"),
+		m.confirmInput.View(),
+		errLine,
+		m.argInputHelp.View(newConfirmKeyMap()),
+	)
+
+	v := tea.NewView(content)
+	v.AltScreen = true
+	return v
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestParseConfirmPatterns(t *testing.T) {
+	got, err := parseConfirmPatterns(" release*, deploy:*,,db:drop ")
+	if err != nil {
+		t.Fatalf("parseConfirmPatterns() error = %v", err)
+	}
+	if want := []string{"release*", "deploy:*", "db:drop"}; !slices.Equal(got, want) {
+		t.Errorf("parseConfirmPatterns() = %q, want %q", got, want)
+	}
+	if _, err := parseConfirmPatterns("deploy:["); !errors.Is(err, ErrInvalidConfirmPattern) {
+		t.Errorf("parseConfirmPatterns(deploy:[) error = %v, want ErrInvalidConfirmPattern", err)
+	}
+}
+
+func TestConfirmReason(t *testing.T) {
+	fileTask := filepath.Join(t.TempDir(), "publish")
+	if err := os.WriteFile(fileTask, []byte("#!/bin/sh\ngit push --tags\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	patterns := []string{"release*", "deploy:*", "db:drop"}
+
+	tests := []struct {
+		name string
+		task loader.Task
+		want string
+	}{
+		{name: "safe task", task: loader.Task{Name: "test", Run: []string{"go test ./..."}}},
+		{name: "name pattern", task: loader.Task{Name: "release:snapshot"}, want: "release:snapshot matches release*"},
+		{name: "namespaced pattern", task: loader.Task{Name: "deploy:web"}, want: "deploy:web matches deploy:*"},
+		{name: "exact name", task: loader.Task{Name: "db:drop"}, want: "db:drop matches db:drop"},
+		{name: "git push", task: loader.Task{Name: "ship", Run: []string{"git push origin main"}}, want: "ship runs git push"},
+		{name: "rm -rf", task: loader.Task{Name: "clean", Run: []string{"rm -rf dist"}}, want: "clean runs rm -rf"},
+		{name: "rm -fr", task: loader.Task{Name: "clean", Run: []string{"rm -fr dist"}}, want: "clean runs rm -fr"},
+		{name: "rm file", task: loader.Task{Name: "clean", Run: []string{"rm -f debug.log"}}},
+		{
+			name: "terraform apply",
+			task: loader.Task{Name: "infra", Run: []string{"cd infra", "terraform apply -auto-approve"}},
+			want: "infra runs terraform apply",
+		},
+		{name: "file task", task: loader.Task{Name: "publish", Source: fileTask}, want: "publish runs git push"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := confirmReason(tt.task, patterns); got != tt.want {
+				t.Errorf("confirmReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardRun(t *testing.T) {
+	m := createTestModel(nil, withTasks(nil))
+	m.confirmPatterns = []string{"release*"}
+
+	ran := 0
+	run := func(m model) (model, tea.Cmd) {
+		ran++
+		return m, nil
+	}
+
+	m, _ = m.guardRun([]loader.Task{{Name: "test"}}, run)
+	if ran != 1 || m.confirm.active() {
+		t.Fatalf("a safe task should run right away, ran %d times", ran)
+	}
+
+	m, _ = m.guardRun([]loader.Task{{Name: "test"}, {Name: "release"}}, run)
+	if ran != 1 || !m.confirm.active() || m.confirm.task != "release" {
+		t.Fatalf("release should wait for confirmation, ran %d times, confirm = %+v", ran, m.confirm)
+	}
+
+	// A wrong name keeps waiting
+	m.confirmInput.SetValue("releas")
+	next, _ := m.handleConfirmInput(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = next.(model)
+	if ran != 1 || !m.confirm.active() || m.confirmErr == "" {
+		t.Fatalf("a wrong name should not run the task, ran %d times", ran)
+	}
+
+	m.confirmInput.SetValue("release")
+	next, _ = m.handleConfirmInput(tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = next.(model)
+	if ran != 2 || m.confirm.active() {
+		t.Errorf("typing the name should run the task, ran %d times", ran)
+	}
+
+	// Esc cancels
+	m, _ = m.guardRun([]loader.Task{{Name: "release"}}, run)
+	next, _ = m.handleConfirmInput(tea.KeyPressMsg{Code: tea.KeyEscape})
+	m = next.(model)
+	if ran != 2 || m.confirm.active() {
+		t.Errorf("Esc should cancel the run, ran %d times", ran)
+	}
+}
+
+func TestGuardRun_Dependencies(t *testing.T) {
+	m := createTestModel(nil, withTasks(nil))
+	m.confirmPatterns = []string{"release*"}
+	m.tasks = []loader.Task{
+		{Name: "ci", Depends: []string{"lint:*", "build --release"}},
+		{Name: "lint:go", Run: []string{"golangci-lint run"}},
+		{Name: "build", DependsPost: []string{"publish"}},
+		{Name: "publish", Run: []string{"git push --tags"}},
+		{Name: "cd", Depends: []string{"release"}},
+		{Name: "release", Depends: []string{"cd"}},
+	}
+
+	ran := 0
+	run := func(m model) (model, tea.Cmd) {
+		ran++
+		return m, nil
+	}
+
+	// A dangerous script two dependencies away still asks first
+	m, _ = m.guardRun([]loader.Task{m.taskByName("ci")}, run)
+	if ran != 0 || m.confirm.task != "ci" {
+		t.Fatalf("ci should wait for confirmation, ran %d times, confirm = %+v", ran, m.confirm)
+	}
+	if want := []string{"ci depends on publish: publish runs git push"}; !slices.Equal(m.confirm.reasons, want) {
+		t.Errorf("reasons = %q, want %q", m.confirm.reasons, want)
+	}
+
+	// Cycles end
+	m.confirm = pendingConfirm{}
+	m, _ = m.guardRun([]loader.Task{m.taskByName("cd")}, run)
+	if want := []string{"cd depends on release: release matches release*"}; !slices.Equal(m.confirm.reasons, want) {
+		t.Errorf("reasons = %q, want %q", m.confirm.reasons, want)
+	}
+
+	m.confirm = pendingConfirm{}
+	m, _ = m.guardRun([]loader.Task{m.taskByName("lint:go")}, run)
+	if ran != 1 || m.confirm.active() {
+		t.Errorf("a task without dangerous dependencies should run right away, ran %d times", ran)
+	}
+}
@@ -7,28 +7,30 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/table"
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
 	"github.com/google/shlex"
 	"github.com/muesli/reflow/wordwrap"
-	"github.com/sahilm/fuzzy"
 
 	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/scaffold"
+	"github.com/rshep3087/prep/internal/spool"
+	"github.com/rshep3087/prep/internal/state"
 	"github.com/rshep3087/prep/internal/watcher"
 )
 
-// debounceInterval is the minimum time between file change reloads.
-const debounceInterval = 500 * time.Millisecond
-
 // Key constants for common key bindings.
 const (
 	keyEsc      = "esc"
@@ -36,9 +38,6 @@ const (
 	keyAltEnter = "alt+enter"
 )
 
-// minToolRowFields is the minimum number of fields expected in a tool table row (name, version).
-const minToolRowFields = 2
-
 // Source priority constants for sorting.
 // Lower values = higher priority (closer to current directory).
 const (
@@ -107,52 +106,263 @@ func (m model) sourcePriority(sourcePath string) int {
 	return priorityUnknown
 }
 
-// filterTasks filters tasks using fuzzy matching against name and description.
-func filterTasks(tasks []loader.Task, filter string) []loader.Task {
-	if filter == "" {
-		return tasks
+// applyFilter re-applies the filter of the given table and updates its rows.
+func (m model) applyFilter(section int, resetCursor bool) model {
+	switch section {
+	case focusTasks:
+		return m.applyTaskFilter(resetCursor)
+	case focusTools:
+		return m.applyToolFilter(resetCursor)
+	case focusEnvVars:
+		return m.applyEnvVarFilter(resetCursor)
+	}
+	return m
+}
+
+// applyTaskFilter applies the tasks filter and updates table rows.
+func (m model) applyTaskFilter(resetCursor bool) model {
+	filterValue := m.filters[focusTasks].input.Value()
+	if filterValue == "" {
+		m.filteredTasks = m.tasks
+		m.taskMatches = nil
+	} else {
+		matches := filterTasks(m.tasks, filterValue, taskFrecency(m.runHistory(), time.Now()))
+		m.filteredTasks = make([]loader.Task, len(matches))
+		m.taskMatches = make(map[string]taskMatch, len(matches))
+		for i, match := range matches {
+			m.filteredTasks[i] = match.task
+			m.taskMatches[match.task.Name] = match
+		}
 	}
 
-	// Create searchable strings (name + description for each task)
-	var sources []string
-	taskMap := make(map[int]loader.Task)
-	for i, task := range tasks {
-		// Fuzzy match against name and description combined
-		sources = append(sources, task.Name+" "+task.Description)
-		taskMap[i] = task
+	m.tasksTable.SetRows(m.taskRows(m.filteredTasks))
+
+	if resetCursor && len(m.filteredTasks) > 0 {
+		m.tasksTable.SetCursor(0)
 	}
+	return m
+}
 
-	// Use fuzzy.Find for intelligent matching
-	matches := fuzzy.Find(filter, sources)
+// applyToolFilter applies the tools filter and updates table rows.
+func (m model) applyToolFilter(resetCursor bool) model {
+	m.filteredTools, m.toolMatches = filterTools(m.tools, m.filters[focusTools].input.Value())
+	m.toolsTable.SetRows(m.toolRows(m.filteredTools))
 
-	// Build filtered results maintaining fuzzy match order (best matches first)
-	filtered := make([]loader.Task, 0, len(matches))
-	for _, match := range matches {
-		filtered = append(filtered, taskMap[match.Index])
+	if resetCursor && len(m.filteredTools) > 0 {
+		m.toolsTable.SetCursor(0)
 	}
+	return m
+}
 
-	return filtered
+// applyEnvVarFilter applies the env vars filter and updates table rows.
+func (m model) applyEnvVarFilter(resetCursor bool) model {
+	m.filteredEnvVars, m.envVarMatches = filterEnvVars(m.envVars, m.filters[focusEnvVars].input.Value())
+	m.envVarsTable.SetRows(m.envVarRows(m.filteredEnvVars))
+
+	if resetCursor && len(m.filteredEnvVars) > 0 {
+		m.envVarsTable.SetCursor(0)
+	}
+	return m
 }
 
-// applyTaskFilter applies filter and updates table rows.
-func (m model) applyTaskFilter(resetCursor bool) model {
-	filterValue := m.filterInput.Value()
-	if filterValue == "" {
-		m.filteredTasks = m.tasks
-	} else {
-		m.filteredTasks = filterTasks(m.tasks, filterValue)
+// taskRows builds tasks table rows, marking favorites, highlighting filter
+// matches and rendering hidden tasks dimmed.
+func (m model) taskRows(tasks []loader.Task) []table.Row {
+	cols := m.tasksTable.Columns()
+	favorites := m.favorites()
+	rows := make([]table.Row, 0, len(tasks))
+	for _, task := range tasks {
+		name := task.Name
+		if slices.Contains(favorites, task.Name) {
+			name = favoriteMarker + name
+		}
+		mark := unmarkedBox
+		if slices.Contains(m.markedTasks, task.Name) {
+			mark = markedBox
+		}
+		row := table.Row{
+			mark,
+			name,
+			strings.Join(task.Aliases, ", "),
+			task.Description,
+			formatSourcePath(task.Source),
+		}
+
+		match := m.taskMatches[task.Name]
+		offsets := [][]int{
+			nil,
+			shiftOffsets(match.name, len(name)-len(task.Name)),
+			match.aliases,
+			match.desc,
+			nil,
+		}
+		base := lipgloss.NewStyle()
+		if task.Hide {
+			base = m.styles.hiddenTask
+		}
+		for i := range min(len(row), len(cols)) {
+			if !task.Hide && len(offsets[i]) == 0 {
+				continue
+			}
+			row[i] = m.renderCell(row[i], offsets[i], cols[i].Width, base)
+		}
+		rows = append(rows, row)
 	}
+	return rows
+}
 
-	rows := make([]table.Row, 0, len(m.filteredTasks))
-	for _, task := range m.filteredTasks {
-		rows = append(rows, table.Row{task.Name, task.Description, formatSourcePath(task.Source)})
+// toolRows builds tools table rows, highlighting filter matches.
+func (m model) toolRows(tools []loader.Tool) []table.Row {
+	rows := make([]table.Row, 0, len(tools))
+	for _, tool := range tools {
+		row := table.Row{
+			tool.Name,
+			tool.Version,
+			tool.RequestedVersion,
+			formatSourcePath(tool.SourcePath),
+		}
+		rows = append(rows, m.highlightRow(row, m.toolsTable.Columns(), m.toolMatches[toolMatchKey(tool)].offsets))
 	}
-	m.tasksTable.SetRows(rows)
+	return rows
+}
 
-	if resetCursor && len(rows) > 0 {
-		m.tasksTable.SetCursor(0)
+// envVarRows builds env vars table rows, masking hidden values and highlighting filter matches.
+func (m model) envVarRows(envVars []loader.EnvVar) []table.Row {
+	rows := make([]table.Row, 0, len(envVars))
+	for _, ev := range envVars {
+		displayValue := maskValue(ev.Value)
+		if !ev.Masked {
+			displayValue = ev.Value
+		}
+		row := table.Row{ev.Name, displayValue}
+		rows = append(rows, m.highlightRow(row, m.envVarsTable.Columns(), m.envVarMatches[ev.Name].offsets))
 	}
-	return m
+	return rows
+}
+
+// highlightRow highlights the matched offsets of each cell in row.
+func (m model) highlightRow(row table.Row, cols []table.Column, offsets [][]int) table.Row {
+	for i := range min(len(row), len(cols), len(offsets)) {
+		if len(offsets[i]) > 0 {
+			row[i] = m.renderCell(row[i], offsets[i], cols[i].Width, lipgloss.NewStyle())
+		}
+	}
+	return row
+}
+
+// renderCell styles a table cell in base, highlighting the runes at offsets.
+func (m model) renderCell(value string, offsets []int, width int, base lipgloss.Style) string {
+	return fitStyledCell(value, width, func(s string) string {
+		return highlightRunes(s, offsets, base, m.styles.match)
+	})
+}
+
+// selectedTask returns the task under the cursor in the tasks table.
+func (m model) selectedTask() (loader.Task, bool) {
+	idx := m.tasksTable.Cursor()
+	if idx < 0 || idx >= len(m.filteredTasks) {
+		return loader.Task{}, false
+	}
+	return m.filteredTasks[idx], true
+}
+
+// selectedToolRow returns the tool under the cursor in the tools table.
+func (m model) selectedToolRow() (loader.Tool, bool) {
+	idx := m.toolsTable.Cursor()
+	if idx < 0 || idx >= len(m.filteredTools) {
+		return loader.Tool{}, false
+	}
+	return m.filteredTools[idx], true
+}
+
+// selectedEnvVar returns the env var under the cursor in the env vars table.
+func (m model) selectedEnvVar() (loader.EnvVar, bool) {
+	idx := m.envVarsTable.Cursor()
+	if idx < 0 || idx >= len(m.filteredEnvVars) {
+		return loader.EnvVar{}, false
+	}
+	return m.filteredEnvVars[idx], true
+}
+
+// favorites returns the favorite task names for the current project.
+func (m model) favorites() []string {
+	if m.store == nil {
+		return nil
+	}
+	return m.store.Favorites(m.cwd)
+}
+
+// runHistory returns the task run history for the current project.
+func (m model) runHistory() []state.Run {
+	if m.store == nil {
+		return nil
+	}
+	return m.store.Runs(m.cwd)
+}
+
+// taskUsage summarizes how a task has been used.
+type taskUsage struct {
+	lastRun time.Time
+	count   int
+}
+
+// taskUsageFromRuns summarizes run history per task name.
+func taskUsageFromRuns(runs []state.Run) map[string]taskUsage {
+	usage := make(map[string]taskUsage)
+	for _, run := range runs {
+		u := usage[run.Task]
+		u.count++
+		if run.StartedAt.After(u.lastRun) {
+			u.lastRun = run.StartedAt
+		}
+		usage[run.Task] = u
+	}
+	return usage
+}
+
+// sortTasks orders tasks in place with favorites pinned at the top and the rest
+// ordered by mode. Ties fall back to source priority, then name, so the order
+// doesn't depend on the order tasks were in.
+func sortTasks(
+	tasks []loader.Task, favorites []string, runs []state.Run, mode taskSortMode, priority func(string) int,
+) {
+	usage := taskUsageFromRuns(runs)
+	slices.SortFunc(tasks, func(a, b loader.Task) int {
+		favA := slices.Contains(favorites, a.Name)
+		favB := slices.Contains(favorites, b.Name)
+		if favA != favB {
+			if favA {
+				return -1
+			}
+			return 1
+		}
+
+		switch mode {
+		case sortByRecent:
+			// Newer first
+			if c := usage[b.Name].lastRun.Compare(usage[a.Name].lastRun); c != 0 {
+				return c
+			}
+		case sortByFrequent:
+			// More runs first
+			if c := cmp.Compare(usage[b.Name].count, usage[a.Name].count); c != 0 {
+				return c
+			}
+		case sortBySource, sortModeCount:
+		}
+
+		// Closer to cwd first
+		if c := cmp.Compare(priority(a.Source), priority(b.Source)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
+
+// resortTasks re-applies favorites and the sort mode to the task list and rebuilds the table.
+func (m model) resortTasks() model {
+	sortTasks(m.tasks, m.favorites(), m.runHistory(), m.taskSort, m.sourcePriority)
+	return m.applyTaskFilter(false)
 }
 
 // handleTasksLoaded processes the tasksLoadedMsg and initializes the tasks table.
@@ -166,37 +376,28 @@ func (m model) handleTasksLoaded(msg loader.TasksLoadedMsg) model {
 
 	m.logger.Debug("loaded tasks", "count", len(msg.Tasks))
 
-	// Sort tasks by source priority (closer to cwd = higher priority), then by name
-	slices.SortFunc(msg.Tasks, func(a, b loader.Task) int {
-		priorityA := m.sourcePriority(a.Source)
-		priorityB := m.sourcePriority(b.Source)
-		if c := cmp.Compare(priorityA, priorityB); c != 0 {
-			return c
-		}
-		return cmp.Compare(a.Name, b.Name)
-	})
+	// Sort tasks by favorites and the sort mode, then by source priority
+	// (closer to cwd = higher priority), then by name
+	sortTasks(msg.Tasks, m.favorites(), m.runHistory(), m.taskSort, m.sourcePriority)
 
 	m.tasks = msg.Tasks
 	m.tasksLoading = false
 
-	rows := make([]table.Row, 0, len(m.tasks))
-	for _, task := range m.tasks {
-		rows = append(rows, table.Row{task.Name, task.Description, formatSourcePath(task.Source)})
+	// Watch file task scripts so edits to them reload the task list
+	if m.watcher != nil {
+		if err := m.watcher.Add(taskSourcePaths(m.tasks)...); err != nil {
+			m.logger.Error("error watching task sources", "error", err)
+		}
 	}
 
-	// Update rows on existing table instead of recreating
-	m.tasksTable.SetRows(rows)
+	// Update rows on existing table instead of recreating, keeping any filter
+	// applied and the cursor where it was
+	m = m.applyTaskFilter(false)
 
 	// Re-apply layout settings if we have window dimensions
 	if m.windowWidth > 0 {
 		m = updateTableLayout(m)
 	}
-
-	// Re-apply filter if active (preserve cursor position during reload)
-	if m.filterActive {
-		m = m.applyTaskFilter(false)
-	}
-
 	return m
 }
 
@@ -224,18 +425,8 @@ func (m model) handleToolsLoaded(msg loader.ToolsLoadedMsg) model {
 	m.tools = msg.Tools
 	m.toolsLoading = false
 
-	rows := make([]table.Row, 0, len(m.tools))
-	for _, tool := range m.tools {
-		rows = append(rows, table.Row{
-			tool.Name,
-			tool.Version,
-			tool.RequestedVersion,
-			formatSourcePath(tool.SourcePath),
-		})
-	}
-
 	// Update rows on existing table instead of recreating
-	m.toolsTable.SetRows(rows)
+	m = m.applyToolFilter(false)
 
 	// Re-apply layout settings if we have window dimensions
 	if m.windowWidth > 0 {
@@ -278,17 +469,8 @@ func (m model) handleEnvVarsLoaded(msg loader.EnvVarsLoadedMsg) model {
 	m.envVars = msg.EnvVars
 	m.envVarsLoading = false
 
-	rows := make([]table.Row, 0, len(m.envVars))
-	for _, ev := range m.envVars {
-		displayValue := maskValue(ev.Value)
-		if !ev.Masked {
-			displayValue = ev.Value
-		}
-		rows = append(rows, table.Row{ev.Name, displayValue})
-	}
-
 	// Update rows on existing table instead of recreating
-	m.envVarsTable.SetRows(rows)
+	m = m.applyEnvVarFilter(false)
 
 	// Re-apply layout settings if we have window dimensions
 	if m.windowWidth > 0 {
@@ -311,22 +493,242 @@ func (m model) handleMiseVersion(msg loader.MiseVersionMsg) model {
 // handleTaskOutput appends task output and updates the viewport.
 // Implements a rolling buffer: when output exceeds maxOutputLines.
 func (m model) handleTaskOutput(msg taskOutputMsg) model {
-	m.totalOutputLines++
+	line := outputLine{text: msg.line, stream: msg.stream, at: msg.at}
+	line.display = m.renderOutputLine(line)
+	shown := m.shownCount()
+	m.taskRun = m.received(line, m.logger)
+
+	// Keep matches in sync with the buffer, which may have rolled, matching
+	// only the new line rather than the whole buffer again
+	if m.search.applied() {
+		kept := m.shownCount()
+		visible := m.streams.shows(line.stream)
+		if visible {
+			kept--
+		}
+		m.search = m.search.rolled(shown - kept)
+		if visible {
+			m.search.matches = appendLineMatches(m.search.matches, line.display, kept, m.search.re)
+		}
+	}
+
+	m.viewport.SetContentLines(m.outputDisplayLines())
+	// Don't scroll away from the match being looked at
+	if !m.search.applied() {
+		m.viewport.GotoBottom()
+	}
+	return m
+}
+
+// handleTaskIdle checks whether a task whose output stalled is waiting on input.
+func (m model) handleTaskIdle(msg taskIdleMsg) model {
+	m.taskRun = m.stalled(msg)
+	return m
+}
+
+// awaitsInput reports whether a task whose output stalled looks like it waits
+// on input. Its last output is the line it left unterminated or, if it
+// finished its line, the last line of the same stream.
+func awaitsInput(output []outputLine, msg taskIdleMsg) bool {
+	if msg.partial != "" {
+		return looksLikePrompt(msg.partial, true)
+	}
+	for i := len(output) - 1; i >= 0; i-- {
+		if output[i].stream == msg.stream {
+			return looksLikePrompt(output[i].text, false)
+		}
+	}
+	return false
+}
+
+// startStdinInput opens the input line for the running task's stdin.
+func (m model) startStdinInput() model {
+	if !m.taskRunning || m.stdin == nil {
+		return m
+	}
+	m.stdinInputActive = true
+	m.stdinInput.SetValue("")
+	m.stdinInput.Focus()
+	return m
+}
+
+// closeStdinInput closes the input line without sending anything.
+func (m model) closeStdinInput() model {
+	m.stdinInputActive = false
+	m.stdinInput.Blur()
+	return m
+}
+
+// closeStdin closes the running task's stdin, which it reads as end of file.
+func (m model) closeStdin() model {
+	if m.stdin == nil {
+		return m
+	}
+	m.taskRun = m.taskRun.closeStdin(m.logger)
+	return m.closeStdinInput()
+}
 
-	// Implement rolling buffer: keep only the last maxOutputLines
-	if len(m.output) >= maxOutputLines {
-		m.output = m.output[len(m.output)-(maxOutputLines-1):]
+// handleStdinInput handles key presses while input for the task is being typed.
+// Enter sends the line, even when empty to accept a default answer.
+func (m model) handleStdinInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc:
+		return m.closeStdinInput(), nil
+	case keyEnter:
+		input := m.stdinInput.Value()
+		m = m.closeStdinInput()
+		m.partialOutput = ""
+		m.awaitingInput = false
+		// The input isn't echoed to the output since it may be a password
+		m.logger.Debug("sending input to task", "task", m.runningTask, "bytes", len(input))
+		return m, writeStdin(m.stdin, input)
+	case "ctrl+d":
+		m = m.closeStdin()
+		m.outputNotice = m.styles.help.Render("closed task input")
+		return m, nil
 	}
 
-	m.output = append(m.output, msg.line)
+	var cmd tea.Cmd
+	m.stdinInput, cmd = m.stdinInput.Update(msg)
+	return m, cmd
+}
 
-	// Apply word wrapping if enabled
-	displayLines := wrapOutputLines(m.output, m.viewport.Width(), m.wrapOutput)
-	m.viewport.SetContentLines(displayLines)
-	m.viewport.GotoBottom()
+// handleTaskInputSent reports input that couldn't be written to the task.
+func (m model) handleTaskInputSent(msg taskInputSentMsg) model {
+	if msg.err != nil {
+		m.logger.Error("error sending input to task", "error", msg.err)
+		m.outputNotice = m.styles.err.Render(msg.err.Error())
+	}
 	return m
 }
 
+// appendOutput appends line to a rolling output buffer that keeps the last
+// maxOutputLines, spooling the dropped lines so the full output can still be saved.
+func appendOutput(
+	output []outputLine, s *spool.File, line outputLine, logger *slog.Logger,
+) ([]outputLine, *spool.File) {
+	if len(output) >= maxOutputLines {
+		dropped := len(output) - (maxOutputLines - 1)
+		s = spoolOutput(s, outputTexts(output[:dropped]), logger)
+		output = output[dropped:]
+	}
+	return append(output, line), s
+}
+
+// spoolOutput writes lines dropped from the rolling buffer to the spool file,
+// creating it on first use. Lines that can't be spooled are lost, as they were
+// before spooling existed.
+func spoolOutput(s *spool.File, lines []string, logger *slog.Logger) *spool.File {
+	if s == nil {
+		var err error
+		if s, err = spool.New(); err != nil {
+			logger.Error("error spooling output", "error", err)
+			return nil
+		}
+	}
+	if err := s.Write(lines...); err != nil {
+		logger.Error("error spooling output", "error", err)
+	}
+	return s
+}
+
+// renderOutputLine styles line by its stream and prefixes the timestamp if enabled.
+func (m model) renderOutputLine(line outputLine) string {
+	text := line.text
+	if line.stream == streamStderr {
+		text = m.styles.stderr.Render(text)
+	}
+	if m.showTimestamps {
+		text = m.styles.help.Render(line.at.Format(outputTimeFormat)) + " " + text
+	}
+	return text
+}
+
+// outputTexts returns the text of lines as the task wrote it.
+func outputTexts(lines []outputLine) []string {
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	return texts
+}
+
+// visibleOutput returns the output lines that pass the stream filter.
+func (m model) visibleOutput() []outputLine {
+	if m.streams == showAllStreams {
+		return m.output
+	}
+	visible := make([]outputLine, 0, len(m.output))
+	for _, line := range m.output {
+		if m.streams.shows(line.stream) {
+			visible = append(visible, line)
+		}
+	}
+	return visible
+}
+
+// shownCount returns the number of visible output lines.
+func (m model) shownCount() int {
+	if m.streams == showAllStreams {
+		return len(m.output)
+	}
+	count := 0
+	for _, line := range m.output {
+		if m.streams.shows(line.stream) {
+			count++
+		}
+	}
+	return count
+}
+
+// shownOutput returns the visible output lines as rendered, before search
+// highlighting and wrapping. Search matches index into these lines.
+func (m model) shownOutput() []string {
+	visible := m.visibleOutput()
+	shown := make([]string, len(visible))
+	for i, line := range visible {
+		shown[i] = line.display
+	}
+	return shown
+}
+
+// cycleStreams switches the output view to the next stream filter.
+func (m model) cycleStreams() model {
+	m.streams = (m.streams + 1) % streamFilterCount
+	m.logger.Debug("changed output streams", "streams", m.streams.String())
+	return m.refreshOutput()
+}
+
+// toggleTimestamps shows or hides the time each output line was received.
+func (m model) toggleTimestamps() model {
+	m.showTimestamps = !m.showTimestamps
+	for i := range m.output {
+		m.output[i].display = m.renderOutputLine(m.output[i])
+	}
+	return m.refreshOutput()
+}
+
+// refreshOutput re-runs the search over the shown lines and updates the viewport.
+func (m model) refreshOutput() model {
+	if m.search.applied() {
+		m.search.matches = findOutputMatches(m.shownOutput(), m.search.re)
+		m.search.current = min(max(m.search.current, 0), len(m.search.matches)-1)
+	}
+	m.viewport.SetContentLines(m.outputDisplayLines())
+	return m.showCurrentMatch()
+}
+
+// outputDisplayLines returns the output lines for the viewport, with search
+// matches highlighted and word wrapping applied if enabled.
+func (m model) outputDisplayLines() []string {
+	lines := m.shownOutput()
+	if m.search.applied() {
+		lines = highlightOutputLines(lines, m.search.matches, m.search.current,
+			m.styles.searchMatch, m.styles.searchCurrent)
+	}
+	return wrapOutputLines(lines, m.viewport.Width(), m.wrapOutput)
+}
+
 // wrapOutputLines applies word wrapping to output lines if enabled.
 // Returns the original lines if wrapping is disabled or width is invalid.
 func wrapOutputLines(lines []string, width int, wrapEnabled bool) []string {
@@ -361,14 +763,41 @@ func wrapOutputLines(lines []string, width int, wrapEnabled bool) []string {
 
 // handleTaskDone processes task completion.
 func (m model) handleTaskDone(msg taskDoneMsg) model {
-	m.taskRunning = false
-	m.taskErr = msg.err
-	m.cancelFunc = nil
+	m.taskRun = m.finished(msg, m.logger)
+	m = m.closeStdinInput()
 	if msg.err != nil {
 		m.logger.Error("task finished with error", "task", m.runningTask, "error", msg.err)
 	} else {
 		m.logger.Debug("task finished successfully", "task", m.runningTask)
 	}
+	// The history keeps runs of single tasks that executed
+	if m.dryRun || (m.batch.mode == batchParallel && m.batch.active()) {
+		return m
+	}
+	return m.recordRun(m.record())
+}
+
+// previousRun returns the most recent run of task that has stats.
+func previousRun(runs []state.Run, task string) (state.Run, bool) {
+	for _, run := range slices.Backward(runs) {
+		if run.Task == task && run.Duration > 0 {
+			return run, true
+		}
+	}
+	return state.Run{}, false
+}
+
+// recordRun adds a finished run to the history and re-sorts tasks if the order depends on it.
+func (m model) recordRun(run state.Run) model {
+	if m.store == nil {
+		return m
+	}
+	if err := m.store.RecordRun(m.cwd, run); err != nil {
+		m.logger.Error("error recording run", "task", run.Task, "error", err)
+	}
+	if m.taskSort != sortBySource {
+		m = m.resortTasks()
+	}
 	return m
 }
 
@@ -380,7 +809,9 @@ func (m model) handleConfigFilesLoaded(msg loader.ConfigFilesLoadedMsg) model {
 	}
 	m.configPaths = msg.Paths
 	m.logger.Debug("loaded config files to watch", "count", len(msg.Paths))
-	w, err := watcher.StartFileWatcher(msg.Paths, m.sender)
+	// Task sources may already be known if tasks loaded first
+	paths := append(slices.Clone(msg.Paths), taskSourcePaths(m.tasks)...)
+	w, err := watcher.StartFileWatcher(paths, m.sender)
 	if err != nil {
 		m.logger.Error("error starting file watcher", "error", err)
 		return m
@@ -407,35 +838,99 @@ func (m model) handleInteractiveTaskClosed(msg interactiveTaskClosedMsg) model {
 	} else {
 		m.logger.Debug("interactive task completed successfully", "task", msg.taskName)
 	}
-	return m
+	return m.recordRun(msg.stats.record(msg.taskName, msg.args, msg.env, msg.startedAt))
+}
+
+// reloadTargets describes which mise data needs to be reloaded after files change.
+type reloadTargets struct {
+	tasks   bool
+	tools   bool
+	envVars bool
+}
+
+// reloadTargetsForPaths decides which loaders are affected by a set of changed files.
+// Config files can define tasks, tools and env vars so they reload everything,
+// .tool-versions only declares tools, and any other watched file is a task source.
+func reloadTargetsForPaths(paths, configPaths []string) reloadTargets {
+	var targets reloadTargets
+	for _, p := range paths {
+		switch {
+		case filepath.Base(p) == ".tool-versions":
+			targets.tools = true
+		case slices.Contains(configPaths, p):
+			targets.tasks = true
+			targets.tools = true
+			targets.envVars = true
+		default:
+			targets.tasks = true
+		}
+	}
+	return targets
 }
 
-// handleFileChanged processes file change events with debouncing.
+// handleFileChanged reloads only the mise data affected by the changed files.
+// The watcher has already debounced and coalesced the events.
 func (m model) handleFileChanged(msg watcher.FileChangedMsg) (model, tea.Cmd) {
-	if time.Since(m.lastReload) < debounceInterval {
-		return m, nil
+	targets := reloadTargetsForPaths(msg.Paths, m.configPaths)
+	m.logger.Debug("watched files changed, reloading mise data",
+		"paths", msg.Paths,
+		"tasks", targets.tasks,
+		"tools", targets.tools,
+		"envVars", targets.envVars,
+	)
+
+	ctx := context.Background()
+	var cmds []tea.Cmd
+	if targets.tasks {
+		cmds = append(cmds, loader.LoadMiseTasks(ctx, m.mise, m.showHidden))
+	}
+	if targets.tools {
+		cmds = append(cmds, loader.LoadMiseTools(ctx, m.mise))
+	}
+	if targets.envVars {
+		cmds = append(cmds, loader.LoadMiseEnvVars(ctx, m.mise))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// taskSourcePaths returns the unique, non-empty source files of the given tasks.
+func taskSourcePaths(tasks []loader.Task) []string {
+	var paths []string
+	for _, task := range tasks {
+		if task.Source != "" && !slices.Contains(paths, task.Source) {
+			paths = append(paths, task.Source)
+		}
 	}
-	m.lastReload = time.Now()
-	m.logger.Debug("config file changed, reloading mise data", "path", msg.Path)
-	return m, loader.ReloadMiseData(m.runner)
+	return paths
 }
 
-//nolint:funlen // Function is 106 lines, slightly over 100 limit
+//nolint:funlen // Function is 144 lines, a key map per section
 func (m model) handleMainKeys(msg tea.KeyPressMsg) (model, tea.Cmd, bool) {
 	key := msg.String()
+	m.notice = ""
 
 	globalKeys := map[string]keyHandler{
 		"q": func(m model) (model, tea.Cmd, bool) {
-			watcher.Close(m.watcher)
-			return m, tea.Quit, true
+			m, cmd := m.quit()
+			return m, cmd, true
 		},
 		"ctrl+c": func(m model) (model, tea.Cmd, bool) {
-			watcher.Close(m.watcher)
-			return m, tea.Quit, true
+			m, cmd := m.quit()
+			return m, cmd, true
 		},
 		keyEsc: func(m model) (model, tea.Cmd, bool) {
-			watcher.Close(m.watcher)
-			return m, tea.Quit, true
+			// Esc clears a kept filter before it quits
+			if m.filters[m.focus].applied() {
+				return m.clearFilter(m.focus), nil, true
+			}
+			m, cmd := m.quit()
+			return m, cmd, true
+		},
+		"o": func(m model) (model, tea.Cmd, bool) {
+			return m.reopenJob(), nil, true
+		},
+		"/": func(m model) (model, tea.Cmd, bool) {
+			return m.openFilter(), nil, true
 		},
 		"tab": func(m model) (model, tea.Cmd, bool) {
 			m.tasksTable.Blur()
@@ -489,12 +984,42 @@ func (m model) handleMainKeys(msg tea.KeyPressMsg) (model, tea.Cmd, bool) {
 			}
 			return m.handleTaskCtrlAltEnter()
 		},
-		"/": func(m model) (model, tea.Cmd, bool) {
-			m.filterActive = true
-			m.filterInput.Focus()
-			m.filterInput.SetValue("")
-			m.filteredTasks = m.tasks
-			return m, nil, true
+		"I": func(m model) (model, tea.Cmd, bool) {
+			if len(m.tasks) == 0 {
+				return m, nil, true
+			}
+			return m.handleTaskWithInput()
+		},
+		"n": func(m model) (model, tea.Cmd, bool) {
+			newModel, cmd := m.openTaskWizard()
+			return newModel, cmd, true
+		},
+		"H": func(m model) (model, tea.Cmd, bool) {
+			return m.toggleHiddenTasks()
+		},
+		"f": func(m model) (model, tea.Cmd, bool) {
+			return m.toggleFavorite(), nil, true
+		},
+		"W": func(m model) (model, tea.Cmd, bool) {
+			if len(m.tasks) == 0 {
+				return m, nil, true
+			}
+			return m.watchSelectedTask()
+		},
+		"s": func(m model) (model, tea.Cmd, bool) {
+			return m.cycleTaskSort(), nil, true
+		},
+		"space": func(m model) (model, tea.Cmd, bool) {
+			return m.toggleMark(), nil, true
+		},
+		"R": func(m model) (model, tea.Cmd, bool) {
+			return m.openBatchPrompt(), nil, true
+		},
+		"p": func(m model) (model, tea.Cmd, bool) {
+			if len(m.tasks) == 0 {
+				return m, nil, true
+			}
+			return m.previewSelectedTask()
 		},
 	}
 
@@ -505,6 +1030,12 @@ func (m model) handleMainKeys(msg tea.KeyPressMsg) (model, tea.Cmd, bool) {
 		"u": func(m model) (model, tea.Cmd, bool) {
 			return m.unuseTool()
 		},
+		"i": func(m model) (model, tea.Cmd, bool) {
+			return m.openToolDetail()
+		},
+		"c": func(m model) (model, tea.Cmd, bool) {
+			return m.changeToolVersion()
+		},
 	}
 
 	envKeyHandlers := map[string]keyHandler{
@@ -540,10 +1071,10 @@ func (m model) handleMainKeys(msg tea.KeyPressMsg) (model, tea.Cmd, bool) {
 }
 
 func (m model) handleTaskEnter() (model, tea.Cmd, bool) {
-	selectedRow := m.tasksTable.SelectedRow()
-	if selectedRow != nil {
-		taskName := selectedRow[0]
-		newModel, cmd := m.startTask(taskName)
+	if task, ok := m.selectedTask(); ok {
+		newModel, cmd := m.guardRun([]loader.Task{task}, func(m model) (model, tea.Cmd) {
+			return m.startTask(task.Name, nil)
+		})
 		return newModel, cmd, true
 	}
 
@@ -551,14 +1082,22 @@ func (m model) handleTaskEnter() (model, tea.Cmd, bool) {
 }
 
 func (m model) handleTaskAltEnter() (model, tea.Cmd, bool) {
-	selectedRow := m.tasksTable.SelectedRow()
-	if selectedRow != nil {
-		taskName := selectedRow[0]
+	if task, ok := m.selectedTask(); ok {
 		m.argInputActive = true
-		m.argInputTask = taskName
-		m.argInput.Focus()
-		m.argInput.SetValue("")
-		return m, nil, true
+		m.argInputTask = task.Name
+		return m.resetArgInputs(), nil, true
+	}
+
+	return model{}, nil, false
+}
+
+// handleTaskWithInput runs the selected task with its stdin connected to the output view.
+func (m model) handleTaskWithInput() (model, tea.Cmd, bool) {
+	if task, ok := m.selectedTask(); ok {
+		newModel, cmd := m.guardRun([]loader.Task{task}, func(m model) (model, tea.Cmd) {
+			return m.startTaskWithInput(task.Name)
+		})
+		return newModel, cmd, true
 	}
 
 	return model{}, nil, false
@@ -566,11 +1105,11 @@ func (m model) handleTaskAltEnter() (model, tea.Cmd, bool) {
 
 // handleTaskCtrlEnter runs an interactive task immediately without prompting for arguments.
 func (m model) handleTaskCtrlEnter() (model, tea.Cmd, bool) {
-	selectedRow := m.tasksTable.SelectedRow()
-	if selectedRow != nil {
-		taskName := selectedRow[0]
-		cmd := m.runInteractiveTask(taskName)
-		return m, cmd, true
+	if task, ok := m.selectedTask(); ok {
+		newModel, cmd := m.guardRun([]loader.Task{task}, func(m model) (model, tea.Cmd) {
+			return m, m.runInteractiveTask(task.Name, nil)
+		})
+		return newModel, cmd, true
 	}
 
 	return model{}, nil, false
@@ -578,21 +1117,43 @@ func (m model) handleTaskCtrlEnter() (model, tea.Cmd, bool) {
 
 // handleTaskCtrlAltEnter opens argument input for interactive task execution.
 func (m model) handleTaskCtrlAltEnter() (model, tea.Cmd, bool) {
-	selectedRow := m.tasksTable.SelectedRow()
-	if selectedRow != nil {
-		taskName := selectedRow[0]
+	if task, ok := m.selectedTask(); ok {
 		m.argInputActive = true
 		m.argInputInteractive = true
-		m.argInputTask = taskName
-		m.argInput.Focus()
-		m.argInput.SetValue("")
-		return m, nil, true
+		m.argInputTask = task.Name
+		return m.resetArgInputs(), nil, true
 	}
 
 	return model{}, nil, false
 }
 
+// resetArgInputs empties the argument and environment fields and focuses the arguments.
+func (m model) resetArgInputs() model {
+	m.argInput.SetValue("")
+	m.envInput.SetValue("")
+	m.argInputErr = nil
+	m.envInputFocused = false
+	m.envInput.Blur()
+	m.argInput.Focus()
+	return m
+}
+
+// switchArgInputField moves the focus between the argument and environment fields.
+func (m model) switchArgInputField() model {
+	m.envInputFocused = !m.envInputFocused
+	if m.envInputFocused {
+		m.argInput.Blur()
+		m.envInput.Focus()
+	} else {
+		m.envInput.Blur()
+		m.argInput.Focus()
+	}
+	return m
+}
+
 // handleArgInput handles input when argument input mode is active.
+//
+//nolint:funlen // Function is 61 lines, slightly over 60 limit
 func (m model) handleArgInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyPressMsg); ok {
 		switch keyMsg.String() {
@@ -601,150 +1162,227 @@ func (m model) handleArgInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.argInputActive = false
 			m.argInputInteractive = false // Reset flag
 			m.argInputTask = ""
-			m.argInput.SetValue("")
-			return m, nil
+			return m.resetArgInputs(), nil
+		case "tab", "shift+tab":
+			return m.switchArgInputField(), nil
 		case keyEnter:
-			// Run task with arguments
-			args := m.argInput.Value()
-			taskName := m.argInputTask
-			isInteractive := m.argInputInteractive
-
-			// Deactivate argument input
-			m.argInputActive = false
-			m.argInputInteractive = false // Reset flag
-			m.argInputTask = ""
-			m.argInput.SetValue("")
-
-			// Parse arguments with proper quote handling
-			var argSlice []string
-			if args != "" {
-				var err error
-				argSlice, err = shlex.Split(args)
-				if err != nil {
-					m.logger.Error("failed to parse arguments", "args", args, "error", err)
-					argSlice = strings.Fields(args) // fallback
-				}
-			}
-
-			// Branch on execution mode
-			if isInteractive {
-				return m, m.runInteractiveTask(taskName, argSlice...)
-			}
-			return m.startTask(taskName, argSlice...)
+			return m.submitArgInput(false)
+		case "ctrl+p":
+			return m.submitArgInput(true)
 		}
 	}
 
-	// Pass message to text input for normal editing
+	// Pass message to the focused text input for normal editing
 	var cmd tea.Cmd
-	m.argInput, cmd = m.argInput.Update(msg)
+	if m.envInputFocused {
+		m.envInput, cmd = m.envInput.Update(msg)
+		m.argInputErr = nil
+	} else {
+		m.argInput, cmd = m.argInput.Update(msg)
+	}
 	return m, cmd
 }
 
-// clearFilter deactivates filter mode and restores the full task list.
-func (m model) clearFilter() model {
-	m.filterActive = false
-	m.filterInput.SetValue("")
-	m.filteredTasks = m.tasks
+// submitArgInput runs the task of the argument input with the typed args and
+// environment overrides, or previews what running it would execute.
+func (m model) submitArgInput(preview bool) (model, tea.Cmd) {
+	// Keep the dialog open until the environment overrides are valid
+	env, err := parseEnvOverrides(m.envInput.Value())
+	if err != nil {
+		m.argInputErr = err
+		if !m.envInputFocused {
+			m = m.switchArgInputField()
+		}
+		return m, nil
+	}
+
+	// Run task with arguments
+	args := m.argInput.Value()
+	taskName := m.argInputTask
+	isInteractive := m.argInputInteractive
+
+	// Deactivate argument input
+	m.argInputActive = false
+	m.argInputInteractive = false // Reset flag
+	m.argInputTask = ""
+	m = m.resetArgInputs()
 
-	// Restore full task list
-	rows := make([]table.Row, 0, len(m.tasks))
-	for _, task := range m.tasks {
-		rows = append(rows, table.Row{task.Name, task.Description, formatSourcePath(task.Source)})
+	// Parse arguments with proper quote handling
+	var argSlice []string
+	if args != "" {
+		var err error
+		argSlice, err = shlex.Split(args)
+		if err != nil {
+			m.logger.Error("failed to parse arguments", "args", args, "error", err)
+			argSlice = strings.Fields(args) // fallback
+		}
 	}
-	m.tasksTable.SetRows(rows)
-	if len(rows) > 0 {
-		m.tasksTable.SetCursor(0)
+
+	// Previews execute nothing, so they need no confirming
+	if preview {
+		return m.previewTask(m.taskByName(taskName), env, argSlice...)
 	}
+
+	// Branch on execution mode
+	return m.guardRun([]loader.Task{m.taskByName(taskName)}, func(m model) (model, tea.Cmd) {
+		if isInteractive {
+			return m, m.runInteractiveTask(taskName, env, argSlice...)
+		}
+		return m.startTask(taskName, env, argSlice...)
+	})
+}
+
+// openFilter starts editing the filter of the focused table, keeping any query already applied.
+func (m model) openFilter() model {
+	m.filters[m.focus].editing = true
+	m.filters[m.focus].input.Focus()
+	m.filterKeys = newFilterKeyMap(m.focus == focusTasks)
+	return m.applyFilter(m.focus, false)
+}
+
+// clearFilter clears the filter of the given table and restores all of its rows.
+func (m model) clearFilter(section int) model {
+	m.filters[section].editing = false
+	m.filters[section].input.Blur()
+	m.filters[section].input.SetValue("")
+	return m.applyFilter(section, true)
+}
+
+// keepFilter stops editing the focused filter but keeps its rows filtered,
+// so the table's actions operate on the filtered rows.
+func (m model) keepFilter() model {
+	if m.filters[m.focus].input.Value() == "" {
+		return m.clearFilter(m.focus)
+	}
+	m.filters[m.focus].editing = false
+	m.filters[m.focus].input.Blur()
 	return m
 }
 
-// handleFilterInput handles input when filter mode is active.
+// handleFilterInput handles input while the focused table's filter is being edited.
 func (m model) handleFilterInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	section := m.focus
 	keyMsg, ok := msg.(tea.KeyPressMsg)
 	if !ok {
 		// Update filter input and apply filter in real-time
 		var cmd tea.Cmd
-		m.filterInput, cmd = m.filterInput.Update(msg)
-		m = m.applyTaskFilter(false)
-		return m, cmd
+		m.filters[section].input, cmd = m.filters[section].input.Update(msg)
+		return m.applyFilter(section, false), cmd
 	}
 
 	switch keyMsg.String() {
 	case keyEsc:
-		return m.clearFilter(), nil
-
-	case keyEnter:
-		// Run selected filtered task (deactivate filter to show output)
-		if len(m.filteredTasks) > 0 {
-			cursor := m.tasksTable.Cursor()
-			if cursor >= 0 && cursor < len(m.filteredTasks) {
-				taskName := m.filteredTasks[cursor].Name
-				m.filterActive = false
-				return m.startTask(taskName)
-			}
-		}
-		return m, nil
+		return m.clearFilter(section), nil
 
-	case keyAltEnter:
-		// Open argument input for selected filtered task (deactivate filter)
-		if len(m.filteredTasks) > 0 {
-			cursor := m.tasksTable.Cursor()
-			if cursor >= 0 && cursor < len(m.filteredTasks) {
-				taskName := m.filteredTasks[cursor].Name
-				m.filterActive = false
-				m.argInputActive = true
-				m.argInputTask = taskName
-				m.argInput.Focus()
-				m.argInput.SetValue("")
-				return m, nil
-			}
+	case "up", "down":
+		// Pass navigation keys to the table being filtered
+		var cmd tea.Cmd
+		switch section {
+		case focusTasks:
+			m.tasksTable, cmd = m.tasksTable.Update(msg)
+		case focusTools:
+			m.toolsTable, cmd = m.toolsTable.Update(msg)
+		case focusEnvVars:
+			m.envVarsTable, cmd = m.envVarsTable.Update(msg)
 		}
-		return m, nil
+		return m, cmd
+	}
 
-	case "ctrl+enter":
-		// Open argument input for interactive execution of filtered task
-		if len(m.filteredTasks) > 0 {
-			cursor := m.tasksTable.Cursor()
-			if cursor >= 0 && cursor < len(m.filteredTasks) {
-				taskName := m.filteredTasks[cursor].Name
-				m.filterActive = false
-				m.argInputActive = true
-				m.argInputInteractive = true
-				m.argInputTask = taskName
-				m.argInput.Focus()
-				m.argInput.SetValue("")
-				return m, nil
-			}
+	if section == focusTasks {
+		if newModel, cmd, handled := m.handleTaskFilterKeys(keyMsg); handled {
+			return newModel, cmd
 		}
-		return m, nil
-
-	case "up", "down", "j", "k":
-		// Pass navigation keys to table
-		var cmd tea.Cmd
-		m.tasksTable, cmd = m.tasksTable.Update(msg)
-		return m, cmd
+	} else if keyMsg.String() == keyEnter {
+		return m.keepFilter(), nil
 	}
 
 	// Update filter input and apply filter in real-time
 	// Reset cursor to 0 when filter text changes (user typed a character)
 	var cmd tea.Cmd
-	m.filterInput, cmd = m.filterInput.Update(msg)
-	m = m.applyTaskFilter(true)
-	return m, cmd
+	m.filters[section].input, cmd = m.filters[section].input.Update(msg)
+	return m.applyFilter(section, true), cmd
 }
 
-func (m model) unuseTool() (model, tea.Cmd, bool) {
-	row := m.toolsTable.SelectedRow()
-	if len(row) < minToolRowFields {
+// handleTaskFilterKeys runs or opens the argument input for the selected
+// filtered task. The filter stays applied until the output view closes.
+func (m model) handleTaskFilterKeys(msg tea.KeyPressMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case keyEnter, keyAltEnter, "ctrl+enter":
+	default:
 		return m, nil, false
 	}
 
-	tool := row[0]
-	version := row[1]
-	m.logger.Debug("removing tool", "tool", tool, "version", version)
+	task, ok := m.selectedTask()
+	if !ok {
+		return m, nil, true
+	}
+	m.filters[focusTasks].editing = false
+	m.filters[focusTasks].input.Blur()
+
+	switch msg.String() {
+	case keyEnter:
+		newModel, cmd := m.guardRun([]loader.Task{task}, func(m model) (model, tea.Cmd) {
+			return m.startTask(task.Name, nil)
+		})
+		return newModel, cmd, true
+	case "ctrl+enter":
+		// Open argument input for interactive execution
+		m.argInputInteractive = true
+	}
+	m.argInputActive = true
+	m.argInputTask = task.Name
+	return m.resetArgInputs(), nil, true
+}
+
+// toggleHiddenTasks switches between listing and omitting hidden tasks and reloads the task list.
+func (m model) toggleHiddenTasks() (model, tea.Cmd, bool) {
+	m.showHidden = !m.showHidden
+	if m.showHidden {
+		m.tasksKeys.Hidden.SetHelp("H", "hide hidden")
+	} else {
+		m.tasksKeys.Hidden.SetHelp("H", "show hidden")
+	}
+	m.logger.Debug("toggling hidden tasks", "showHidden", m.showHidden)
+	return m, loader.LoadMiseTasks(context.Background(), m.mise, m.showHidden), true
+}
+
+// toggleFavorite stars or unstars the selected task and moves it in or out of the pinned section.
+func (m model) toggleFavorite() model {
+	task, ok := m.selectedTask()
+	if !ok || m.store == nil {
+		return m
+	}
+	favorite, err := m.store.ToggleFavorite(m.cwd, task.Name)
+	if err != nil {
+		m.logger.Error("error saving favorites", "task", task.Name, "error", err)
+	}
+	m.logger.Debug("toggled favorite", "task", task.Name, "favorite", favorite)
+
+	m = m.resortTasks()
+	// Keep the cursor on the task that was toggled
+	if idx := slices.IndexFunc(m.filteredTasks, func(t loader.Task) bool { return t.Name == task.Name }); idx >= 0 {
+		m.tasksTable.SetCursor(idx)
+	}
+	return m
+}
+
+// cycleTaskSort switches to the next task sort mode.
+func (m model) cycleTaskSort() model {
+	m.taskSort = (m.taskSort + 1) % sortModeCount
+	m.tasksKeys.Sort.SetHelp("s", "sort: "+m.taskSort.String())
+	m.logger.Debug("changed task sort", "mode", m.taskSort.String())
+	return m.resortTasks()
+}
+
+func (m model) unuseTool() (model, tea.Cmd, bool) {
+	tool, ok := m.selectedToolRow()
+	if !ok {
+		return m, nil, false
+	}
+	m.logger.Debug("removing tool", "tool", tool.Name, "version", tool.Version)
 
 	ctx := context.Background()
-	return m, loader.RemoveTool(ctx, m.runner, tool, version), true
+	return m, loader.RemoveTool(ctx, m.mise, tool.Name, tool.Version), true
 }
 
 // editSourceFile opens the source file for the selected task or tool in the editor.
@@ -761,14 +1399,12 @@ func (m model) editSourceFile() (model, tea.Cmd, bool) {
 func (m model) getSelectedSourcePath() string {
 	switch m.focus {
 	case focusTasks:
-		idx := m.tasksTable.Cursor()
-		if idx >= 0 && idx < len(m.tasks) {
-			return m.tasks[idx].Source
+		if task, ok := m.selectedTask(); ok {
+			return task.Source
 		}
 	case focusTools:
-		idx := m.toolsTable.Cursor()
-		if idx >= 0 && idx < len(m.tools) {
-			return m.tools[idx].SourcePath
+		if tool, ok := m.selectedToolRow(); ok {
+			return tool.SourcePath
 		}
 	}
 	return ""
@@ -784,8 +1420,7 @@ func (m model) handleWrapToggle() model {
 	m.wrapOutput = !m.wrapOutput
 
 	// Re-apply content with new wrap state
-	displayLines := wrapOutputLines(m.output, m.viewport.Width(), m.wrapOutput)
-	m.viewport.SetContentLines(displayLines)
+	m.viewport.SetContentLines(m.outputDisplayLines())
 
 	// Restore relative scroll position
 	newTotalHeight := m.viewport.TotalLineCount()
@@ -797,37 +1432,276 @@ func (m model) handleWrapToggle() model {
 	return m
 }
 
+// displayLineOf returns the viewport line where output line idx starts.
+func (m model) displayLineOf(idx int) int {
+	if !m.wrapOutput {
+		return idx
+	}
+	return len(wrapOutputLines(m.shownOutput()[:idx], m.viewport.Width(), true))
+}
+
+// outputLineAt returns the output line shown at viewport line displayLine.
+func (m model) outputLineAt(displayLine int) int {
+	if !m.wrapOutput {
+		return displayLine
+	}
+	shown := m.shownOutput()
+	n := 0
+	for i, line := range shown {
+		n += len(wrapOutputLines([]string{line}, m.viewport.Width(), true))
+		if n > displayLine {
+			return i
+		}
+	}
+	return len(shown)
+}
+
+// startOutputSearch opens the search input. Matches are searched from the
+// top of the viewport, like an incremental search in a pager.
+func (m model) startOutputSearch() model {
+	m.search.editing = true
+	m.search.input.SetValue("")
+	m.search.input.Focus()
+	m.search.from = m.outputLineAt(m.viewport.YOffset())
+	return m
+}
+
+// applyOutputSearch searches the output for the current query and scrolls to
+// the first match after where the search started.
+func (m model) applyOutputSearch() model {
+	m.search.re = compileSearch(m.search.input.Value())
+	m.search.matches = findOutputMatches(m.shownOutput(), m.search.re)
+	m.search.current = nextMatchFrom(m.search.matches, m.search.from)
+	m.viewport.SetContentLines(m.outputDisplayLines())
+	return m.showCurrentMatch()
+}
+
+// showCurrentMatch scrolls the viewport to center the focused match.
+func (m model) showCurrentMatch() model {
+	if m.search.current < 0 || m.search.current >= len(m.search.matches) {
+		return m
+	}
+	line := m.displayLineOf(m.search.matches[m.search.current].line)
+	m.viewport.SetYOffset(max(0, line-m.viewport.Height()/2))
+	return m
+}
+
+// moveMatch focuses the match delta positions away, wrapping around the ends.
+func (m model) moveMatch(delta int) model {
+	n := len(m.search.matches)
+	if n == 0 {
+		return m
+	}
+	m.search.current = ((m.search.current+delta)%n + n) % n
+	m.viewport.SetContentLines(m.outputDisplayLines())
+	return m.showCurrentMatch()
+}
+
+// clearOutputSearch removes the search and its highlights.
+func (m model) clearOutputSearch() model {
+	m.search = newOutputSearch()
+	m.viewport.SetContentLines(m.outputDisplayLines())
+	return m
+}
+
+// jumpToFirstError searches for common error patterns from the top of the
+// output, so n and N move between the errors that follow.
+func (m model) jumpToFirstError() model {
+	m.search.editing = false
+	m.search.input.Blur()
+	m.search.input.SetValue(errorPattern)
+	m.search.from = 0
+	return m.applyOutputSearch()
+}
+
+// startSaveOutput opens the path input for saving the output.
+func (m model) startSaveOutput() model {
+	m.saveInputActive = true
+	m.saveInput.SetValue(defaultOutputPath(m.cwd, m.runningTask))
+	m.saveInput.CursorEnd()
+	m.saveInput.Focus()
+	return m
+}
+
+// handleSaveInput handles key presses while the save path is being edited.
+func (m model) handleSaveInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc:
+		m.saveInputActive = false
+		m.saveInput.Blur()
+		return m, nil
+	case keyEnter:
+		path := strings.TrimSpace(m.saveInput.Value())
+		if path == "" {
+			return m, nil
+		}
+		m.saveInputActive = false
+		m.saveInput.Blur()
+		path = expandOutputPath(path, m.cwd, m.homeDir)
+		m.logger.Debug("saving output", "path", path, "keepANSI", m.keepANSI)
+		return m, saveOutput(path, m.spool.Snapshot(), outputTexts(m.output), !m.keepANSI)
+	}
+
+	var cmd tea.Cmd
+	m.saveInput, cmd = m.saveInput.Update(msg)
+	return m, cmd
+}
+
+// copyVisibleOutput copies the output lines on screen to the clipboard.
+func (m model) copyVisibleOutput() (model, tea.Cmd) {
+	first := m.outputLineAt(m.viewport.YOffset())
+	visible := m.visibleOutput()
+	last := min(m.outputLineAt(m.viewport.YOffset()+m.viewport.Height()-1)+1, len(visible))
+	if first >= last {
+		return m, nil
+	}
+	lines := outputTexts(visible[first:last])
+	var b strings.Builder
+	n, err := writeOutput(&b, strings.NewReader(""), lines, !m.keepANSI)
+	return m.handleOutputCopied(outputCopiedMsg{text: b.String(), lines: n, err: err})
+}
+
+// toggleKeepANSI switches between keeping and stripping ANSI codes when saving or copying.
+func (m model) toggleKeepANSI() model {
+	m.keepANSI = !m.keepANSI
+	if m.keepANSI {
+		m.outputNotice = m.styles.help.Render("saving and copying keeps colors")
+	} else {
+		m.outputNotice = m.styles.help.Render("saving and copying strips colors")
+	}
+	return m
+}
+
+// handleOutputSaved reports the result of saving the output.
+func (m model) handleOutputSaved(msg outputSavedMsg) model {
+	if msg.err != nil {
+		m.logger.Error("error saving output", "error", msg.err)
+		m.outputNotice = m.styles.err.Render(msg.err.Error())
+		return m
+	}
+	m.outputNotice = m.styles.success.Render(fmt.Sprintf("✓ Saved %d lines to %s", msg.lines, msg.path))
+	return m
+}
+
+// handleOutputCopied puts the copied output on the clipboard and reports it.
+func (m model) handleOutputCopied(msg outputCopiedMsg) (model, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("error copying output", "error", msg.err)
+		m.outputNotice = m.styles.err.Render(msg.err.Error())
+		return m, nil
+	}
+	m.outputNotice = m.styles.success.Render(fmt.Sprintf("✓ Copied %d lines", msg.lines))
+	return m, tea.SetClipboard(msg.text)
+}
+
+// handleSearchInput handles key presses while the output search is being edited.
+func (m model) handleSearchInput(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case keyEsc:
+		return m.clearOutputSearch(), nil
+	case keyEnter:
+		if m.search.input.Value() == "" {
+			return m.clearOutputSearch(), nil
+		}
+		// Keep the matches so n and N can move between them
+		m.search.editing = false
+		m.search.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+	return m.applyOutputSearch(), cmd
+}
+
+// closeOutputView returns from the output view to the tables. The spool must
+// have been closed or handed over to a background job.
+func (m model) closeOutputView() model {
+	m = m.stopWatch()
+	m.showOutput = false
+	m.runID = 0
+	m.output = nil
+	m.runningTask = ""
+	m.taskErr = nil
+	m.wrapOutput = false // Reset wrap state
+	m.search = newOutputSearch()
+	m.partialOutput = ""
+	m.awaitingInput = false
+	m.outputNotice = ""
+	m.batch = taskBatch{}
+	m.dryRun = false
+	// Clear filter data when returning from output view (filter may have been used to select task)
+	if m.filters[focusTasks].applied() {
+		m = m.clearFilter(focusTasks)
+	}
+	return m
+}
+
 // handleOutputKeys handles key presses in the output view.
 func (m model) handleOutputKeys(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if m.saveInputActive {
+		return m.handleSaveInput(msg)
+	}
+	if m.search.editing {
+		return m.handleSearchInput(msg)
+	}
+	if m.stdinInputActive {
+		return m.handleStdinInput(msg)
+	}
+	m.outputNotice = ""
+
 	switch msg.String() {
 	case "w":
 		return m.handleWrapToggle(), nil
+	case "s":
+		return m.startSaveOutput(), nil
+	case "y":
+		return m, copyOutput(m.spool.Snapshot(), outputTexts(m.output), !m.keepANSI)
+	case "o":
+		return m.cycleStreams(), nil
+	case "t":
+		return m.toggleTimestamps(), nil
+	case "Y":
+		return m.copyVisibleOutput()
+	case "a":
+		return m.toggleKeepANSI(), nil
+	case "/":
+		return m.startOutputSearch(), nil
+	case "n":
+		return m.moveMatch(1), nil
+	case "N":
+		return m.moveMatch(-1), nil
+	case "e":
+		return m.jumpToFirstError(), nil
+	case "i":
+		return m.startStdinInput(), nil
 	case "q", keyEsc:
+		// Esc clears a search before it closes the view
+		if msg.String() == keyEsc && m.search.applied() {
+			return m.clearOutputSearch(), nil
+		}
 		// Close output view (only if task is not running)
 		if !m.taskRunning {
-			m.showOutput = false
-			m.output = nil
-			m.runningTask = ""
-			m.taskErr = nil
-			m.wrapOutput = false // Reset wrap state
-			// Clear filter data when returning from output view (filter may have been used to select task)
-			if len(m.filteredTasks) > 0 && len(m.filteredTasks) < len(m.tasks) {
-				m = m.clearFilter()
-			}
-			return m, nil
+			spool.Close(m.spool)
+			m.spool = nil
+			return m.closeOutputView(), nil
 		}
 		return m, nil
+	case "b":
+		return m.detachRun(), nil
+	case "W":
+		return m.toggleWatch(), nil
 	case "ctrl+c":
-		// Cancel running task
+		// Cancel running task, it keeps running until its process group has stopped
 		if m.taskRunning && m.cancelFunc != nil {
-			m.logger.Debug("cancelling task", "task", m.runningTask)
+			m.logger.Debug("cancelling task", "task", m.runningTask, "grace", m.stopGrace)
 			m.cancelFunc()
+			m.taskStopping = true
 			return m, nil
 		}
 		// If not running, quit the app
 		if !m.taskRunning {
-			watcher.Close(m.watcher)
-			return m, tea.Quit
+			return m.quit()
 		}
 		return m, nil
 	}
@@ -849,16 +1723,12 @@ func maskValue(value string) string {
 
 // showSelectedEnvVar unmasks the currently selected environment variable.
 func showSelectedEnvVar(m model) model {
-	if len(m.envVars) == 0 {
-		return m
-	}
-	selectedRow := m.envVarsTable.SelectedRow()
-	if selectedRow == nil {
+	selected, ok := m.selectedEnvVar()
+	if !ok {
 		return m
 	}
-	selectedName := selectedRow[0]
 	for i := range m.envVars {
-		if m.envVars[i].Name == selectedName {
+		if m.envVars[i].Name == selected.Name {
 			m.envVars[i].Masked = false
 			break
 		}
@@ -883,78 +1753,88 @@ func hideAllEnvVars(m model) model {
 }
 
 // refreshEnvVarsTable rebuilds the env vars table rows based on current mask state.
+// The filter is re-applied because only unmasked values are searchable.
 func refreshEnvVarsTable(m model) model {
-	rows := make([]table.Row, 0, len(m.envVars))
-	for _, ev := range m.envVars {
-		displayValue := maskValue(ev.Value)
-		if !ev.Masked {
-			displayValue = ev.Value
-		}
-		rows = append(rows, table.Row{ev.Name, displayValue})
-	}
-
-	// Update rows on existing table instead of recreating
-	m.envVarsTable.SetRows(rows)
-
-	return m
+	return m.applyEnvVarFilter(false)
 }
 
-// runTask executes a mise task and streams output back to the TUI.
-func runTask(ctx context.Context, taskName string, sender messageSender, args ...string) tea.Cmd {
+// outputDrainDelay is how long a run waits for output after mise exited.
+// Children a task left running in the background can hold stdout and stderr
+// open, the run is over without their later output.
+const outputDrainDelay = time.Second
+
+// runTask executes the mise command cmdArgs that runs tasks and streams its
+// output back to the TUI. The tasks run in their own process group. Cancelling ctx
+// stops the whole group, killing it if it hasn't exited after stopGrace.
+// stdin is the read end of the pipe the task reads its input from, runTask
+// closes it once the task has started. A nil stdin runs the task without input. env holds KEY=VALUE
+// overrides of prep's environment. Messages about the run carry id so they
+// reach it after it was sent to the background.
+func runTask(
+	ctx context.Context, id int, cmdArgs []string, env []string, stopGrace time.Duration, stdin *os.File,
+	sender messageSender,
+) tea.Cmd {
 	return func() tea.Msg {
-		cmdArgs := []string{"mise", "run", taskName}
-		// If there are arguments, add -- separator so mise passes them to the task
-		if len(args) > 0 {
-			cmdArgs = append(cmdArgs, "--")
-			cmdArgs = append(cmdArgs, args...)
+		//nolint:gosec // cmdArgs are controlled: mise binary is configured, task names from config, args from user
+		cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
+		cmd.Env = commandEnv(os.Environ(), env)
+		setProcessGroup(cmd)
+		if stdin != nil {
+			cmd.Stdin = stdin
+			// The task has its own copy once started
+			defer func() { _ = stdin.Close() }()
 		}
-		//nolint:gosec // cmdArgs are controlled: mise command is hardcoded, taskName from config, args from user
-		cmd := exec.CommandContext(ctx, cmdArgs[0], cmdArgs[1:]...)
 
-		// Create pipes for stdout and stderr
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return taskDoneMsg{err: fmt.Errorf("failed to create stdout pipe: %w", err)}
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return taskDoneMsg{err: fmt.Errorf("failed to create stderr pipe: %w", err)}
-		}
+		// Output is copied into pipes, so Wait returns once the task has exited
+		// and its output drained, or outputDrainDelay later when children it
+		// left running in the background keep stdout or stderr open
+		stdout, stdoutWriter := io.Pipe()
+		stderr, stderrWriter := io.Pipe()
+		cmd.Stdout = stdoutWriter
+		cmd.Stderr = stderrWriter
+		cmd.WaitDelay = outputDrainDelay
 
+		startedAt := time.Now()
 		if startErr := cmd.Start(); startErr != nil {
-			return taskDoneMsg{err: fmt.Errorf("failed to start task: %w", startErr)}
+			return taskDoneMsg{run: id, err: fmt.Errorf("failed to start task: %w", startErr), stats: newRunStats(nil, 0)}
 		}
-
-		// Stream stdout
-		go func() {
-			scanner := bufio.NewScanner(stdout)
-			for scanner.Scan() {
-				sender.Send(taskOutputMsg{line: scanner.Text()})
-			}
-		}()
-
-		// Stream stderr
-		go func() {
-			scanner := bufio.NewScanner(stderr)
-			for scanner.Scan() {
-				sender.Send(taskOutputMsg{line: scanner.Text()})
-			}
-		}()
-
-		// Wait for the command to finish
-		err = cmd.Wait()
-		return taskDoneMsg{err: err}
+		stopWatching := stopOnCancel(ctx, cmd, stopGrace)
+		defer stopWatching()
+
+		// Stream stdout and stderr, tagging each line with its stream and when it
+		// arrived, and report when a stream stalls since the task may be waiting on input
+		var wg sync.WaitGroup
+		stream := func(r io.Reader, s outputStream) {
+			defer wg.Done()
+			readOutput(r, outputIdleDelay,
+				func(line string) { sender.Send(taskOutputMsg{run: id, line: line, stream: s, at: time.Now()}) },
+				func(partial string) { sender.Send(taskIdleMsg{run: id, stream: s, partial: partial}) },
+			)
+		}
+		wg.Add(2) //nolint:mnd // stdout and stderr
+		go stream(stdout, streamStdout)
+		go stream(stderr, streamStderr)
+
+		err := cmd.Wait()
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// The task succeeded, only its background children still write output
+			err = nil
+		}
+		duration := time.Since(startedAt)
+		_ = stdoutWriter.Close()
+		_ = stderrWriter.Close()
+		wg.Wait()
+		if ctx.Err() != nil {
+			// Processes that ignored the interrupt may still be running, the
+			// watch kills them once the grace period is over
+			waitForProcessGroup(cmd.Process.Pid, stopGrace+time.Second)
+		}
+		return taskDoneMsg{run: id, err: err, stats: newRunStats(cmd.ProcessState, duration)}
 	}
 }
 
-// startTask initializes and starts a task execution.
-func (m model) startTask(taskName string, args ...string) (model, tea.Cmd) {
-	m.logger.Debug("starting task", "task", taskName, "args", args)
-
-	// Create cancellable context
-	ctx, cancel := context.WithCancel(context.Background())
-
-	// Initialize viewport
+// newOutputViewport creates the output viewport sized to the window.
+func (m model) newOutputViewport() viewport.Model {
 	width := m.windowWidth
 	height := m.windowHeight
 	if width == 0 {
@@ -964,24 +1844,92 @@ func (m model) startTask(taskName string, args ...string) (model, tea.Cmd) {
 		height = 24
 	}
 
-	m.viewport = viewport.New(
+	vp := viewport.New(
 		viewport.WithWidth(width),
 		viewport.WithHeight(height-viewportHeaderFooterHeight),
 	)
 
 	// Enable high performance rendering for alternate screen buffer
-	m.viewport.YPosition = 0
+	vp.YPosition = 0
+	return vp
+}
 
+// startTask initializes and starts a task execution. env holds KEY=VALUE
+// environment overrides for this run.
+func (m model) startTask(taskName string, env []string, args ...string) (model, tea.Cmd) {
+	m = m.resetOutputView()
+	return m.launchTasks([]string{taskName}, env, args...)
+}
+
+// resetOutputView shows an empty output view for a new run. A batch of
+// marked tasks shown in the view is dropped.
+func (m model) resetOutputView() model {
+	m.viewport = m.newOutputViewport()
 	m.showOutput = true
+	m.output = []outputLine{}
+	m.streams = showAllStreams
+	m.totalOutputLines = 0
+	m.search = newOutputSearch()
+	spool.Close(m.spool)
+	m.spool = nil
+	m.outputNotice = ""
+	m.batch = taskBatch{}
+	m.dryRun = false
+	return m
+}
+
+// startTaskWithInput starts a task in raw mode with a pipe for its stdin, so
+// the output view can answer its prompts.
+func (m model) startTaskWithInput(taskName string) (model, tea.Cmd) {
+	m = m.resetOutputView()
+	return m.launchRun(taskName, taskCommand([]string{taskName}, nil, true), nil, nil, true)
+}
+
+// launchTasks starts a run of tasks in the output view, keeping the output
+// already shown. Several tasks run in parallel in a single mise run.
+func (m model) launchTasks(tasks []string, env []string, args ...string) (model, tea.Cmd) {
+	taskName := strings.Join(tasks, " "+parallelSeparator+" ")
+	return m.launchRun(taskName, taskCommand(tasks, args, false), env, args, false)
+}
+
+// launchRun starts mise with cmdArgs, the arguments running taskName with args, in
+// the output view. withStdin gives it a pipe for its stdin.
+func (m model) launchRun(taskName string, cmdArgs, env, args []string, withStdin bool) (model, tea.Cmd) {
+	m.logger.Debug("starting task", "task", taskName, "args", args, "env", env)
+
+	// Create cancellable context
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.lastRunID++
+	m.runID = m.lastRunID
 	m.runningTask = taskName
+	m.runningArgs = args
+	m.runningEnv = env
+	m.runStartedAt = time.Now()
+	m.runStats = runStats{}
+	m.taskStopping = false
+	m.previousRun, _ = previousRun(m.runHistory(), taskName)
 	m.taskRunning = true
 	m.taskErr = nil
-	m.output = []string{}
-	m.totalOutputLines = 0
 	m.cancelFunc = cancel
 
+	// Give the task a pipe for its stdin, the output view writes to it
+	m = m.closeStdin()
+	m.partialOutput = ""
+	var stdin *os.File
+	if withStdin {
+		var stdinWriter *os.File
+		var err error
+		if stdin, stdinWriter, err = os.Pipe(); err != nil {
+			m.logger.Error("error creating stdin pipe, task runs without input", "error", err)
+			stdin = nil
+		} else {
+			m.stdin = stdinWriter
+		}
+	}
+
 	return m, tea.Batch(
-		runTask(ctx, taskName, m.sender, args...),
+		runTask(ctx, m.runID, m.mise.CommandLine(cmdArgs), env, m.stopGrace, stdin, m.sender),
 		m.taskSpinner.Tick,
 	)
 }
@@ -1006,10 +1954,52 @@ func (m model) openToolPicker() (model, tea.Cmd, bool) {
 	m.toolList.Title = "Select a Tool to Install"
 	m.toolList.SetShowStatusBar(true)
 	m.toolList.SetFilteringEnabled(true)
+	m.toolList.AdditionalShortHelpKeys = newToolListKeyMap().ShortHelp
 
 	// Start loading registry
 	ctx := context.Background()
-	return m, loader.LoadMiseRegistry(ctx, m.runner), true
+	return m, loader.LoadMiseRegistry(ctx, m.mise, m.toolCache()), true
+}
+
+// toolCache returns the cache of the registry and remote versions, nil when
+// there is no cache directory or the mise version isn't known yet. Entries of
+// other mise versions aren't used.
+func (m model) toolCache() *loader.Cache {
+	return loader.NewCache(m.cacheDir, m.miseVersion)
+}
+
+// pruneToolCache removes old entries from the tool cache in the background.
+func (m model) pruneToolCache() tea.Cmd {
+	cache := m.toolCache()
+	if cache == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := cache.Prune(); err != nil {
+			m.logger.Warn("error pruning the tool cache", "error", err)
+		}
+		return nil
+	}
+}
+
+// refreshRegistry loads the registry again, bypassing the cache.
+func (m model) refreshRegistry() tea.Cmd {
+	m.logger.Debug("refreshing registry")
+	ctx := context.Background()
+	return tea.Batch(
+		m.toolList.NewStatusMessage("Refreshing registry..."),
+		loader.LoadMiseRegistry(ctx, m.mise, m.toolCache().Bypass()),
+	)
+}
+
+// refreshVersions loads the versions of the selected tool again, bypassing the cache.
+func (m model) refreshVersions() tea.Cmd {
+	m.logger.Debug("refreshing versions", "tool", m.selectedTool)
+	ctx := context.Background()
+	return tea.Batch(
+		m.versionList.NewStatusMessage("Refreshing versions..."),
+		loader.LoadToolVersions(ctx, m.mise, m.toolCache().Bypass(), m.selectedTool),
+	)
 }
 
 // closeToolPicker closes the tool picker and resets state.
@@ -1019,41 +2009,76 @@ func (m model) closeToolPicker() model {
 	m.selectedTool = ""
 	m.selectedVersion = ""
 	m.versionsLoading = false
+	m.customTool = false
+	m.customToolErr = ""
+	m.customToolInput.SetValue("")
+	m.customToolInput.Blur()
+	m.pickerChange = loader.Tool{}
+	m.versionChoices = versionChoices{}
 	return m
 }
 
-// handleRegistryLoaded processes the registry loaded message.
-func (m model) handleRegistryLoaded(msg loader.RegistryLoadedMsg) model {
+// handleRegistryLoaded processes the registry loaded message. A registry
+// from a stale cache entry is shown while it is refreshed.
+func (m model) handleRegistryLoaded(msg loader.RegistryLoadedMsg) (model, tea.Cmd) {
 	if msg.Err != nil {
 		m.logger.Error("error loading registry", "error", msg.Err)
+		// A failed refresh keeps showing the cached tools
+		if len(m.toolList.Items()) > 0 {
+			return m, nil
+		}
 		m.pickerState = pickerClosed
-		return m
+		return m, nil
 	}
 
 	m.logger.Debug("loaded registry", "count", len(msg.Tools))
 
-	// Convert to list items
-	items := make([]list.Item, len(msg.Tools))
-	for i, tool := range msg.Tools {
-		items[i] = toolItem{name: tool.Name, backend: tool.Backend}
+	// Convert to list items, after the entry for tools that aren't in the registry
+	items := make([]list.Item, 0, len(msg.Tools)+1)
+	items = append(items, customToolItem{})
+	for _, tool := range msg.Tools {
+		items = append(items, toolItem{name: tool.Name, backend: tool.Backend, backends: tool.Backends})
 	}
 
-	m.toolList.SetItems(items)
-	return m
+	cmd := m.toolList.SetItems(items)
+	if msg.Stale {
+		return m, tea.Batch(cmd, m.refreshRegistry())
+	}
+	return m, cmd
 }
 
-// handleVersionsLoaded processes the versions loaded message.
-func (m model) handleVersionsLoaded(msg loader.VersionsLoadedMsg) model {
+// handleVersionsLoaded processes the versions loaded message. Versions from
+// a stale cache entry are shown while they are refreshed, and refreshed
+// versions replace the listed ones in place.
+func (m model) handleVersionsLoaded(msg loader.VersionsLoadedMsg) (model, tea.Cmd) {
+	if msg.Tool != m.selectedTool {
+		return m, nil
+	}
+	if m.pickerState == pickerSelectVersion {
+		return m.handleVersionsRefreshed(msg), nil
+	}
+	if m.pickerState != pickerLoadingVersions {
+		return m, nil
+	}
 	m.versionsLoading = false
 
 	if msg.Err != nil {
 		m.logger.Error("error loading versions", "error", msg.Err)
+		if m.changingVersion() {
+			return m.closeToolPicker(), nil
+		}
+		if m.customTool {
+			return m.rejectCustomTool(msg.Err.Error()), nil
+		}
 		// Go back to tool selection
 		m.pickerState = pickerSelectTool
-		return m
+		return m, nil
 	}
 
-	m.logger.Debug("loaded versions", "tool", msg.Tool, "count", len(msg.Versions))
+	m.logger.Debug("loaded versions", "tool", msg.Tool, "count", len(msg.Versions), "stale", msg.Stale)
+	if m.customTool && len(msg.Versions) == 0 {
+		return m.rejectCustomTool(fmt.Sprintf("mise lists no versions of %s", msg.Tool)), nil
+	}
 
 	// Initialize version list
 	delegate := list.NewDefaultDelegate()
@@ -1066,18 +2091,46 @@ func (m model) handleVersionsLoaded(msg loader.VersionsLoadedMsg) model {
 		height = 24
 	}
 
-	items := make([]list.Item, len(msg.Versions))
-	for i, v := range msg.Versions {
-		items[i] = versionItem{version: v}
+	m.versionChoices = newVersionChoices(msg)
+	if m.changingVersion() {
+		m.versionChoices.requested = m.pickerChange.RequestedVersion
+		m.versionChoices.active = m.pickerChange.Version
 	}
 
-	m.versionList = list.New(items, delegate, width, height-pickerListPadding)
-	m.versionList.Title = fmt.Sprintf("Select version for: %s", m.selectedTool)
+	m.versionList = list.New(nil, delegate, width, height-pickerListPadding)
 	m.versionList.SetShowStatusBar(true)
 	m.versionList.SetFilteringEnabled(true)
+	m.versionList.AdditionalShortHelpKeys = newVersionListKeyMap().ShortHelp
+	m = m.setVersionItems("")
 
 	m.pickerState = pickerSelectVersion
-	return m
+	if msg.Stale {
+		return m, m.refreshVersions()
+	}
+	return m, nil
+}
+
+// handleVersionsRefreshed replaces the listed versions with refreshed ones,
+// keeping the cursor on the selected version. A failed refresh keeps the
+// versions listed.
+func (m model) handleVersionsRefreshed(msg loader.VersionsLoadedMsg) model {
+	if msg.Err != nil {
+		m.logger.Error("error refreshing versions", "tool", msg.Tool, "error", msg.Err)
+		return m
+	}
+	m.logger.Debug("refreshed versions", "tool", msg.Tool, "count", len(msg.Versions))
+	prereleases := m.versionChoices.prereleases
+	m.versionChoices = newVersionChoices(msg)
+	m.versionChoices.prereleases = prereleases
+	if m.changingVersion() {
+		m.versionChoices.requested = m.pickerChange.RequestedVersion
+		m.versionChoices.active = m.pickerChange.Version
+	}
+	selected := ""
+	if v, ok := m.versionList.SelectedItem().(versionItem); ok {
+		selected = v.version
+	}
+	return m.setVersionItems(selected)
 }
 
 // handleToolInstalled processes the tool installed message.
@@ -1085,16 +2138,18 @@ func (m model) handleToolInstalled(msg loader.ToolInstalledMsg) (model, tea.Cmd)
 	if msg.Err != nil {
 		m.logger.Error("error installing tool", "tool", msg.Tool, "version", msg.Version, "error", msg.Err)
 		m.pickerState = pickerClosed
+		m.pickerChange = loader.Tool{}
 		return m, nil
 	}
 
 	m.logger.Debug("tool installed", "tool", msg.Tool, "version", msg.Version)
 	m.pickerState = pickerClosed
 	m.selectedTool = ""
+	m.pickerChange = loader.Tool{}
 
 	// Reload tools to show the new tool
 	ctx := context.Background()
-	return m, loader.LoadMiseTools(ctx, m.runner)
+	return m, loader.LoadMiseTools(ctx, m.mise)
 }
 
 // handleToolRemoved processes the tool removed message.
@@ -1108,7 +2163,7 @@ func (m model) handleToolRemoved(msg loader.ToolRemovedMsg) (model, tea.Cmd) {
 
 	// Reload tools to reflect the removal
 	ctx := context.Background()
-	return m, loader.LoadMiseTools(ctx, m.runner)
+	return m, loader.LoadMiseTools(ctx, m.mise)
 }
 
 // handlePickerUpdate handles all messages when the picker is open.
@@ -1122,10 +2177,10 @@ func (m model) handlePickerUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleWindowSize(msg), nil
 
 	case loader.RegistryLoadedMsg:
-		return m.handleRegistryLoaded(msg), nil
+		return m.handleRegistryLoaded(msg)
 
 	case loader.VersionsLoadedMsg:
-		return m.handleVersionsLoaded(msg), nil
+		return m.handleVersionsLoaded(msg)
 
 	case loader.ToolInstalledMsg:
 		return m.handleToolInstalled(msg)
@@ -1136,6 +2191,10 @@ func (m model) handlePickerUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch m.pickerState {
 	case pickerSelectTool:
 		m.toolList, cmd = m.toolList.Update(msg)
+	case pickerSelectBackend:
+		m.backendList, cmd = m.backendList.Update(msg)
+	case pickerEnterCustomTool:
+		m.customToolInput, cmd = m.customToolInput.Update(msg)
 	case pickerSelectVersion:
 		m.versionList, cmd = m.versionList.Update(msg)
 	case pickerSelectConfig:
@@ -1154,6 +2213,10 @@ func (m model) handlePickerKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 		return m, nil
 	case pickerSelectTool:
 		return m.handleToolListKeys(msg)
+	case pickerSelectBackend:
+		return m.handleBackendListKeys(msg)
+	case pickerEnterCustomTool:
+		return m.handleCustomToolKeys(msg)
 	case pickerSelectVersion:
 		return m.handleVersionListKeys(msg)
 	case pickerSelectConfig:
@@ -1179,18 +2242,22 @@ func (m model) handleToolListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 	switch msg.String() {
 	case keyEsc, "q":
 		return m.closeToolPicker(), nil
+	case "ctrl+r":
+		return m, m.refreshRegistry()
 	case keyEnter:
 		if item := m.toolList.SelectedItem(); item != nil {
+			m.backendList = list.Model{}
+			if _, ok := item.(customToolItem); ok {
+				return m.openCustomTool()
+			}
 			tool, ok := item.(toolItem)
 			if !ok {
 				return m, nil
 			}
-			m.selectedTool = tool.name
-			m.pickerState = pickerLoadingVersions
-			m.versionsLoading = true
-			m.logger.Debug("loading versions for tool", "tool", tool.name)
-			ctx := context.Background()
-			return m, loader.LoadToolVersions(ctx, m.runner, tool.name)
+			if len(tool.backends) > 1 {
+				return m.openBackendPicker(tool), nil
+			}
+			return m.loadPickerVersions(tool.name)
 		}
 		return m, nil
 	}
@@ -1201,6 +2268,81 @@ func (m model) handleToolListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 	return m, cmd
 }
 
+// loadPickerVersions loads the versions of tool, a registry name or a backend spec.
+func (m model) loadPickerVersions(tool string) (model, tea.Cmd) {
+	m.selectedTool = tool
+	m.pickerState = pickerLoadingVersions
+	m.versionsLoading = true
+	m.logger.Debug("loading versions for tool", "tool", tool)
+	ctx := context.Background()
+	return m, loader.LoadToolVersions(ctx, m.mise, m.toolCache(), tool)
+}
+
+// openBackendPicker opens the list of backends tool can be installed with.
+func (m model) openBackendPicker(tool toolItem) model {
+	m.logger.Debug("opening backend picker", "tool", tool.name, "backends", tool.backends)
+	m.pickerState = pickerSelectBackend
+
+	delegate := list.NewDefaultDelegate()
+	width := m.windowWidth
+	height := m.windowHeight
+	if width == 0 {
+		width = 80
+	}
+	if height == 0 {
+		height = 24
+	}
+
+	items := make([]list.Item, len(tool.backends))
+	for i, backend := range tool.backends {
+		items[i] = backendItem{tool: tool.name, backend: backend, isDefault: i == 0}
+	}
+
+	m.backendList = list.New(items, delegate, width, height-pickerListPadding)
+	m.backendList.Title = fmt.Sprintf("Select backend for: %s", tool.name)
+	m.backendList.SetShowStatusBar(false)
+	m.backendList.SetFilteringEnabled(true)
+	return m
+}
+
+// handleBackendListKeys handles keys when selecting a backend.
+func (m model) handleBackendListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
+	// If the list is filtering, let it handle all keys (including esc to cancel filter)
+	if m.backendList.FilterState() == list.Filtering {
+		var cmd tea.Cmd
+		m.backendList, cmd = m.backendList.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m.closeToolPicker(), nil
+	case keyEsc:
+		// Go back to tool selection
+		m.pickerState = pickerSelectTool
+		return m, nil
+	case keyEnter:
+		if item := m.backendList.SelectedItem(); item != nil {
+			backend, ok := item.(backendItem)
+			if !ok {
+				return m, nil
+			}
+			// The default backend keeps the plain name in the config, others
+			// are installed as backend:tool@version
+			if backend.isDefault {
+				return m.loadPickerVersions(backend.tool)
+			}
+			return m.loadPickerVersions(backend.backend)
+		}
+		return m, nil
+	}
+
+	// Let list handle other keys (navigation, filtering)
+	var cmd tea.Cmd
+	m.backendList, cmd = m.backendList.Update(msg)
+	return m, cmd
+}
+
 // handleVersionListKeys handles keys when selecting a version.
 func (m model) handleVersionListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 	// If the list is filtering, let it handle all keys (including esc to cancel filter)
@@ -1213,9 +2355,25 @@ func (m model) handleVersionListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
 		return m.closeToolPicker(), nil
+	case "p":
+		return m.togglePrereleases(), nil
+	case "ctrl+r":
+		return m, m.refreshVersions()
 	case keyEsc:
-		// Go back to tool selection
-		m.pickerState = pickerSelectTool
+		// A version change started from the tools table, not the tool list
+		if m.changingVersion() {
+			return m.closeToolPicker(), nil
+		}
+		// Go back to the custom tool, backend selection, or tool selection
+		switch {
+		case m.customTool:
+			m.pickerState = pickerEnterCustomTool
+			return m, m.customToolInput.Focus()
+		case len(m.backendList.Items()) > 0:
+			m.pickerState = pickerSelectBackend
+		default:
+			m.pickerState = pickerSelectTool
+		}
 		return m, nil
 	case keyEnter:
 		if item := m.versionList.SelectedItem(); item != nil {
@@ -1223,6 +2381,10 @@ func (m model) handleVersionListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 			if !ok {
 				return m, nil
 			}
+			// The new version goes to the config file that requested the old one
+			if m.changingVersion() {
+				return m.installChangedVersion(version.version)
+			}
 			m.selectedVersion = version.version
 			m.logger.Debug(
 				"version selected, showing config picker",
@@ -1302,7 +2464,7 @@ func (m model) handleConfigListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 			)
 			ctx := context.Background()
 			return m, loader.InstallTool(
-				ctx, m.runner, m.selectedTool, m.selectedVersion, config.path,
+				ctx, m.mise, m.selectedTool, m.selectedVersion, config.path,
 			)
 		}
 		return m, nil
@@ -1314,6 +2476,202 @@ func (m model) handleConfigListKeys(msg tea.KeyPressMsg) (model, tea.Cmd) {
 	return m, cmd
 }
 
+// ErrTaskExists is returned when the wizard is given the name of an existing task.
+var ErrTaskExists = errors.New("task already exists")
+
+// openTaskWizard opens the new task wizard at the name step.
+func (m model) openTaskWizard() (model, tea.Cmd) {
+	m.logger.Debug("opening new task wizard")
+	m.wizardSpec = scaffold.TaskSpec{}
+	return m.enterWizardStep(wizardName)
+}
+
+// closeTaskWizard closes the new task wizard and resets its state.
+func (m model) closeTaskWizard() model {
+	m.logger.Debug("closing new task wizard")
+	m.wizardStep = wizardClosed
+	m.wizardSpec = scaffold.TaskSpec{}
+	m.wizardErr = nil
+	m.wizardInput.Blur()
+	m.wizardInput.SetValue("")
+	m.wizardScript.Blur()
+	m.wizardScript.SetValue("")
+	return m
+}
+
+// enterWizardStep moves the wizard to step and prepares its input.
+func (m model) enterWizardStep(step wizardStep) (model, tea.Cmd) {
+	m.wizardStep = step
+	m.wizardErr = nil
+	m.wizardKeys = newWizardKeyMap(step == wizardRun)
+
+	switch step {
+	case wizardName:
+		m.wizardInput.Placeholder = "build or namespace:task"
+		m.wizardInput.SetValue(m.wizardSpec.Name)
+		return m, m.wizardInput.Focus()
+	case wizardDescription:
+		m.wizardInput.Placeholder = "What the task does (optional)"
+		m.wizardInput.SetValue(m.wizardSpec.Description)
+		return m, m.wizardInput.Focus()
+	case wizardRun:
+		m.wizardInput.Blur()
+		m.wizardScript.SetValue(m.wizardSpec.Run)
+		return m, m.wizardScript.Focus()
+	case wizardDepends:
+		m.wizardScript.Blur()
+		m.wizardInput.Placeholder = "lint, test (optional)"
+		m.wizardInput.SetValue(strings.Join(m.wizardSpec.Depends, ", "))
+		return m, m.wizardInput.Focus()
+	case wizardTarget:
+		m.wizardInput.Blur()
+		return m.openWizardTargets(), nil
+	case wizardClosed, wizardWriting:
+	}
+	return m, nil
+}
+
+// openWizardTargets builds the list of destinations for the new task:
+// every TOML config file mise knows about plus a file task under mise-tasks/.
+func (m model) openWizardTargets() model {
+	width := m.windowWidth
+	height := m.windowHeight
+	if width == 0 {
+		width = 80
+	}
+	if height == 0 {
+		height = 24
+	}
+
+	var items []list.Item
+	for _, path := range m.configPaths {
+		if filepath.Ext(path) == ".toml" {
+			items = append(items, targetItem{path: path})
+		}
+	}
+	items = append(items, targetItem{path: scaffold.FileTaskPath(m.cwd, m.wizardSpec.Name), fileTask: true})
+
+	m.wizardTargets = list.New(items, list.NewDefaultDelegate(), width, height-pickerListPadding)
+	m.wizardTargets.Title = fmt.Sprintf("Where should %s be defined?", m.wizardSpec.Name)
+	m.wizardTargets.SetShowStatusBar(false)
+	m.wizardTargets.SetFilteringEnabled(false)
+	return m
+}
+
+// validateNewTaskName checks that name is valid and not already defined.
+func (m model) validateNewTaskName(name string) error {
+	if err := scaffold.ValidateName(name); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(m.tasks, func(t loader.Task) bool { return t.Name == name }) {
+		return fmt.Errorf("%w: %s", ErrTaskExists, name)
+	}
+	return nil
+}
+
+// handleWizardKeys handles key presses while the new task wizard is open.
+func (m model) handleWizardKeys(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == keyEsc {
+		return m.closeTaskWizard(), nil
+	}
+
+	var cmd tea.Cmd
+	switch m.wizardStep {
+	case wizardName, wizardDescription, wizardDepends:
+		if msg.String() == keyEnter {
+			return m.submitWizardInput()
+		}
+		m.wizardInput, cmd = m.wizardInput.Update(msg)
+	case wizardRun:
+		// Alt+Enter is bound to the textarea's newline action
+		if msg.String() == keyEnter {
+			return m.submitWizardInput()
+		}
+		m.wizardScript, cmd = m.wizardScript.Update(msg)
+	case wizardTarget:
+		if msg.String() == keyEnter {
+			return m.submitWizardTarget()
+		}
+		m.wizardTargets, cmd = m.wizardTargets.Update(msg)
+	case wizardClosed, wizardWriting:
+	}
+	return m, cmd
+}
+
+// submitWizardInput validates the current step's answer and advances the wizard.
+func (m model) submitWizardInput() (model, tea.Cmd) {
+	value := strings.TrimSpace(m.wizardInput.Value())
+
+	switch m.wizardStep {
+	case wizardName:
+		if err := m.validateNewTaskName(value); err != nil {
+			m.wizardErr = err
+			return m, nil
+		}
+		m.wizardSpec.Name = value
+		return m.enterWizardStep(wizardDescription)
+	case wizardDescription:
+		m.wizardSpec.Description = value
+		return m.enterWizardStep(wizardRun)
+	case wizardRun:
+		script := m.wizardScript.Value()
+		if strings.TrimSpace(script) == "" {
+			m.wizardErr = scaffold.ErrRunRequired
+			return m, nil
+		}
+		m.wizardSpec.Run = script
+		return m.enterWizardStep(wizardDepends)
+	case wizardDepends:
+		m.wizardSpec.Depends = scaffold.ParseDepends(value)
+		return m.enterWizardStep(wizardTarget)
+	case wizardClosed, wizardTarget, wizardWriting:
+	}
+	return m, nil
+}
+
+// submitWizardTarget writes the task to the selected destination.
+func (m model) submitWizardTarget() (model, tea.Cmd) {
+	target, ok := m.wizardTargets.SelectedItem().(targetItem)
+	if !ok {
+		return m, nil
+	}
+	m.wizardStep = wizardWriting
+	m.logger.Debug("creating task", "task", m.wizardSpec.Name, "path", target.path, "fileTask", target.fileTask)
+	return m, createTask(m.wizardSpec, target, m.cwd)
+}
+
+// createTask returns a Cmd that writes spec to target.
+func createTask(spec scaffold.TaskSpec, target targetItem, root string) tea.Cmd {
+	return func() tea.Msg {
+		if target.fileTask {
+			path, err := scaffold.WriteFileTask(root, spec)
+			return taskCreatedMsg{name: spec.Name, path: path, fileTask: true, err: err}
+		}
+		err := scaffold.AppendToConfig(target.path, spec)
+		return taskCreatedMsg{name: spec.Name, path: target.path, err: err}
+	}
+}
+
+// handleTaskCreated processes the result of writing a new task.
+func (m model) handleTaskCreated(msg taskCreatedMsg) (model, tea.Cmd) {
+	if msg.err != nil {
+		m.logger.Error("error creating task", "task", msg.name, "error", msg.err)
+		m.wizardStep = wizardTarget
+		m.wizardErr = msg.err
+		return m, nil
+	}
+
+	m.logger.Debug("task created", "task", msg.name, "path", msg.path)
+	m = m.closeTaskWizard()
+
+	// Config files are watched, so appending to one triggers a reload on its own.
+	// New file tasks aren't watched yet, so reload tasks explicitly.
+	if msg.fileTask {
+		return m, loader.LoadMiseTasks(context.Background(), m.mise, m.showHidden)
+	}
+	return m, nil
+}
+
 func (m model) handleWindowSize(msg tea.WindowSizeMsg) tea.Model {
 	m.windowWidth = msg.Width
 	m.windowHeight = msg.Height
@@ -1325,10 +2683,18 @@ func (m model) handleWindowSize(msg tea.WindowSizeMsg) tea.Model {
 	m.outputHelp.SetWidth(msg.Width)
 	m.argInputHelp.SetWidth(msg.Width)
 	m.filterHelp.SetWidth(msg.Width)
+	m.wizardHelp.SetWidth(msg.Width)
+	m.wizardInput.SetWidth(min(msg.Width-tablePadding, defaultInputWidth))
+	m.wizardScript.SetWidth(min(msg.Width-tablePadding, defaultInputWidth))
+	if m.wizardStep == wizardTarget {
+		m.wizardTargets.SetSize(msg.Width, msg.Height-pickerListPadding)
+	}
 
 	switch m.pickerState {
 	case pickerSelectTool:
 		m.toolList.SetSize(msg.Width, msg.Height-pickerListPadding)
+	case pickerSelectBackend:
+		m.backendList.SetSize(msg.Width, msg.Height-pickerListPadding)
 	case pickerSelectVersion:
 		m.versionList.SetSize(msg.Width, msg.Height-pickerListPadding)
 	case pickerSelectConfig:
@@ -1346,8 +2712,7 @@ func (m model) handleWindowSize(msg tea.WindowSizeMsg) tea.Model {
 		m.viewport.SetHeight(msg.Height - viewportHeaderFooterHeight)
 
 		// Re-apply content with wrapping at new width
-		displayLines := wrapOutputLines(m.output, m.viewport.Width(), m.wrapOutput)
-		m.viewport.SetContentLines(displayLines)
+		m.viewport.SetContentLines(m.outputDisplayLines())
 
 		// Restore relative scroll position
 		if oldTotalHeight > 0 && m.viewport.TotalLineCount() > 0 {
@@ -1392,11 +2757,14 @@ var _ tea.ExecCommand = &interactiveTaskCommand{}
 // interactiveTaskCommand implements tea.ExecCommand to run a mise task
 // interactively and wait for user confirmation before returning to the TUI.
 type interactiveTaskCommand struct {
+	mise     *loader.Client
 	taskName string
 	args     []string
+	env      []string // KEY=VALUE environment overrides
 	stdin    io.Reader
 	stdout   io.Writer
 	stderr   io.Writer
+	stats    runStats // set once the task has finished
 }
 
 // Run executes the task and waits for user confirmation.
@@ -1406,15 +2774,20 @@ func (c *interactiveTaskCommand) Run() error {
 		cmdArgs = append(cmdArgs, "--")
 		cmdArgs = append(cmdArgs, c.args...)
 	}
+	cmdArgs = c.mise.CommandLine(cmdArgs)
 
-	cmd := exec.CommandContext(context.Background(), "mise", cmdArgs...)
+	//nolint:gosec // the mise binary is configured by the user, task names from config, args from user
+	cmd := exec.CommandContext(context.Background(), cmdArgs[0], cmdArgs[1:]...)
+	cmd.Env = commandEnv(os.Environ(), c.env)
 
 	cmd.Stdin = c.stdin
 	cmd.Stdout = c.stdout
 	cmd.Stderr = c.stderr
 
 	// Run the command and capture the error
+	startedAt := time.Now()
 	err := cmd.Run()
+	c.stats = newRunStats(cmd.ProcessState, time.Since(startedAt))
 
 	// Determine the exit code from the error
 	exitCode := 0
@@ -1435,6 +2808,7 @@ func (c *interactiveTaskCommand) Run() error {
 	} else {
 		fmt.Fprintf(c.stdout, "Task failed with exit code %d.\n", exitCode)
 	}
+	fmt.Fprintf(c.stdout, "Took %s.\n", formatDuration(c.stats.duration))
 	fmt.Fprintln(c.stdout, "Press Enter to return to the task list.")
 
 	// Wait for user to press Enter
@@ -1462,18 +2836,26 @@ func (c *interactiveTaskCommand) SetStderr(w io.Writer) {
 
 // runInteractiveTask suspends the TUI and executes a mise task with full
 // terminal access, then waits for user confirmation before returning.
-func (m model) runInteractiveTask(taskName string, args ...string) tea.Cmd {
-	m.logger.Debug("launching interactive task", "task", taskName, "args", args)
+// env holds KEY=VALUE environment overrides for this run.
+func (m model) runInteractiveTask(taskName string, env []string, args ...string) tea.Cmd {
+	m.logger.Debug("launching interactive task", "task", taskName, "args", args, "env", env)
 
 	cmd := &interactiveTaskCommand{
+		mise:     m.mise,
 		taskName: taskName,
 		args:     args,
+		env:      env,
 	}
 
+	startedAt := time.Now()
 	return tea.Exec(cmd, func(err error) tea.Msg {
 		return interactiveTaskClosedMsg{
-			taskName: taskName,
-			err:      err,
+			taskName:  taskName,
+			args:      args,
+			env:       env,
+			startedAt: startedAt,
+			stats:     cmd.stats,
+			err:       err,
 		}
 	})
 }
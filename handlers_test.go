@@ -1,12 +1,24 @@
 package main
 
 import (
+	"errors"
+	"log/slog"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/table"
+	"charm.land/bubbles/v2/textinput"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
 
 	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/scaffold"
+	"github.com/rshep3087/prep/internal/state"
+	"github.com/rshep3087/prep/internal/watcher"
 )
 
 func TestSourcePriority(t *testing.T) {
@@ -175,7 +187,82 @@ func TestMaskValue(t *testing.T) {
 }
 
 // createTestModel creates a minimal model for testing handlers.
-func createTestModel(envVars []loader.EnvVar) model {
+// testModelOption sets up part of the model createTestModel returns.
+type testModelOption func(m *model)
+
+// withRunningTask shows the output of task, running as run id.
+func withRunningTask(task string, id int) testModelOption {
+	return func(m *model) {
+		m.showOutput = true
+		m.viewport = m.newOutputViewport()
+		m.runID = id
+		m.lastRunID = id
+		m.runningTask = task
+		m.taskRunning = true
+		m.cancelFunc = func() {}
+	}
+}
+
+// withWatch shows task running as run 1 with a watch on its sources.
+func withWatch(t *testing.T, task string) testModelOption {
+	t.Helper()
+	w, err := watcher.StartFileWatcher(nil, nil)
+	if err != nil {
+		t.Fatalf("StartFileWatcher failed: %v", err)
+	}
+	t.Cleanup(func() { watcher.Close(w) })
+
+	return func(m *model) {
+		withRunningTask(task, 1)(m)
+		m.lastWatchID = 1
+		m.watch = taskWatch{id: 1, task: task, sources: []string{"**/*.go"}, watcher: w, runs: 1}
+	}
+}
+
+// withTasks lists tasks, with the marks set.
+func withTasks(tasks []string, marked ...string) testModelOption {
+	return func(m *model) {
+		m.tasksTable = newTable(getTasksTableConfig(), nil, true)
+		for _, name := range tasks {
+			m.tasks = append(m.tasks, loader.Task{Name: name})
+		}
+		m.filteredTasks = m.tasks
+		m.markedTasks = marked
+		m.tasksTable.SetRows(m.taskRows(m.tasks))
+	}
+}
+
+// withToolDetail opens the detail pane of tool while its details load.
+func withToolDetail(tool string) testModelOption {
+	return func(m *model) {
+		m.toolDetail = toolDetailPane{open: true, loading: true, tool: tool}
+	}
+}
+
+// pressKeys sends each key to the model through Update, like typing them,
+// and returns the model and the command of the last key.
+func pressKeys(t *testing.T, m model, keys ...string) (model, tea.Cmd) {
+	t.Helper()
+	special := map[string]tea.KeyPressMsg{
+		"space": {Code: tea.KeySpace, Text: " "},
+		"down":  {Code: tea.KeyDown},
+		"tab":   {Code: tea.KeyTab},
+		keyEsc:  {Code: tea.KeyEscape},
+	}
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg, ok := special[k]
+		if !ok {
+			msg = tea.KeyPressMsg{Code: []rune(k)[0], Text: k}
+		}
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(model)
+	}
+	return m, cmd
+}
+
+func createTestModel(envVars []loader.EnvVar, opts ...testModelOption) model {
 	// Create table with env vars
 	rows := make([]table.Row, len(envVars))
 	for i, ev := range envVars {
@@ -188,10 +275,23 @@ func createTestModel(envVars []loader.EnvVar) model {
 
 	envVarsTable := newTable(getEnvVarsTableConfig(), rows, true)
 
-	return model{
-		envVars:      envVars,
-		envVarsTable: envVarsTable,
+	m := model{
+		envVars:         envVars,
+		filteredEnvVars: envVars,
+		envVarsTable:    envVarsTable,
+		logger:          slog.New(slog.DiscardHandler),
+		styles:          newStyles(),
+		search:          newOutputSearch(),
+		argInputHelp:    initHelpModel(),
+		argInput:        textinput.New(),
+		envInput:        textinput.New(),
+		confirmInput:    textinput.New(),
+		customToolInput: textinput.New(),
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return m
 }
 
 func TestShowSelectedEnvVar(t *testing.T) {
@@ -340,3 +440,333 @@ func TestEnvVarVisibilityToggleCycle(t *testing.T) {
 		}
 	}
 }
+
+func TestReloadTargetsForPaths(t *testing.T) {
+	configPaths := []string{
+		"/home/user/project/mise.toml",
+		"/home/user/project/.tool-versions",
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  reloadTargets
+	}{
+		{
+			name:  "config file reloads everything",
+			paths: []string{"/home/user/project/mise.toml"},
+			want:  reloadTargets{tasks: true, tools: true, envVars: true},
+		},
+		{
+			name:  "tool-versions reloads only tools",
+			paths: []string{"/home/user/project/.tool-versions"},
+			want:  reloadTargets{tools: true},
+		},
+		{
+			name:  "file task script reloads only tasks",
+			paths: []string{"/home/user/project/mise-tasks/build"},
+			want:  reloadTargets{tasks: true},
+		},
+		{
+			name: "mixed changes combine targets",
+			paths: []string{
+				"/home/user/project/.tool-versions",
+				"/home/user/project/mise-tasks/build",
+			},
+			want: reloadTargets{tasks: true, tools: true},
+		},
+		{
+			name:  "no paths reloads nothing",
+			paths: nil,
+			want:  reloadTargets{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reloadTargetsForPaths(tt.paths, configPaths)
+			if got != tt.want {
+				t.Errorf("reloadTargetsForPaths(%v) = %+v, want %+v", tt.paths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNewTaskName(t *testing.T) {
+	m := model{
+		tasks: []loader.Task{{Name: "build"}, {Name: "db:migrate"}},
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "new task name", input: "lint"},
+		{name: "new namespaced name", input: "db:seed"},
+		{name: "existing task", input: "build", wantErr: ErrTaskExists},
+		{name: "existing namespaced task", input: "db:migrate", wantErr: ErrTaskExists},
+		{name: "invalid name", input: "my task", wantErr: scaffold.ErrInvalidName},
+		{name: "empty name", input: "", wantErr: scaffold.ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.validateNewTaskName(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateNewTaskName(%q) = %v, want %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectedTask_UsesDisplayedTasks(t *testing.T) {
+	tasks := []loader.Task{
+		{Name: "build"},
+		{Name: "secret", Hide: true},
+	}
+	m := model{
+		tasksTable: newTable(getTasksTableConfig(), nil, true),
+		styles:     newStyles(),
+		tasks:      tasks,
+	}
+	m.filteredTasks = tasks
+	m.tasksTable.SetRows(m.taskRows(tasks))
+	m.tasksTable.MoveDown(1)
+
+	task, ok := m.selectedTask()
+	if !ok {
+		t.Fatal("expected a selected task")
+	}
+	// The table cell is styled for hidden tasks, but the selected task keeps its plain name
+	if task.Name != "secret" {
+		t.Errorf("selectedTask().Name = %q, want %q", task.Name, "secret")
+	}
+}
+
+func TestSortTasks(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	runs := []state.Run{
+		{Task: "lint", StartedAt: base},
+		{Task: "lint", StartedAt: base.Add(time.Minute)},
+		{Task: "lint", StartedAt: base.Add(2 * time.Minute)},
+		{Task: "test", StartedAt: base.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		favorites []string
+		mode      taskSortMode
+		want      []string
+	}{
+		{
+			name: "source order is kept without favorites",
+			mode: sortBySource,
+			want: []string{"build", "lint", "release", "test"},
+		},
+		{
+			name:      "favorites are pinned in source order",
+			favorites: []string{"test", "release"},
+			mode:      sortBySource,
+			want:      []string{"release", "test", "build", "lint"},
+		},
+		{
+			name: "recent puts the latest run first",
+			mode: sortByRecent,
+			want: []string{"test", "lint", "build", "release"},
+		},
+		{
+			name: "frequent puts the most runs first",
+			mode: sortByFrequent,
+			want: []string{"lint", "test", "build", "release"},
+		},
+		{
+			name:      "favorites stay above frequently run tasks",
+			favorites: []string{"release"},
+			mode:      sortByFrequent,
+			want:      []string{"release", "lint", "test", "build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []loader.Task{{Name: "test"}, {Name: "release"}, {Name: "lint"}, {Name: "build"}}
+			sortTasks(tasks, tt.favorites, runs, tt.mode, func(string) int { return 0 })
+
+			got := make([]string, len(tasks))
+			for i, task := range tasks {
+				got[i] = task.Name
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sortTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortTasks_BackToSource(t *testing.T) {
+	m := createTestModel(nil)
+	m.cwd = "/p"
+	m.tasksTable = newTable(getTasksTableConfig(), nil, true)
+	m.tasks = []loader.Task{
+		{Name: "lint", Source: "/p/mise.toml"},
+		{Name: "build", Source: "/p/mise.toml"},
+		{Name: "deploy", Source: "/p/sub/mise.toml"},
+		{Name: "a", Source: "/home/mise.toml"},
+	}
+	names := func() []string {
+		var got []string
+		for _, task := range m.tasks {
+			got = append(got, task.Name)
+		}
+		return got
+	}
+	m = m.resortTasks()
+	source := names()
+	if want := []string{"build", "lint", "deploy", "a"}; !slices.Equal(source, want) {
+		t.Fatalf("source order = %v, want %v", source, want)
+	}
+
+	// Recent and back restores the source order, not the recent one
+	store, err := state.Open(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordRun(m.cwd, state.Run{Task: "a", StartedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	m.store = store
+	m.taskSort = sortByRecent
+	m = m.resortTasks()
+	if got := names(); got[0] != "a" {
+		t.Fatalf("recent order = %v, want a first", got)
+	}
+	m.taskSort = sortBySource
+	m = m.resortTasks()
+	if got := names(); !slices.Equal(got, source) {
+		t.Errorf("order after switching back = %v, want %v", got, source)
+	}
+}
+
+func TestFilter_ActionsUseFilteredRow(t *testing.T) {
+	m := createTestModel([]loader.EnvVar{
+		{Name: "API_KEY", Value: "key456", Masked: true},
+		{Name: "DATABASE_URL", Value: "postgres://localhost", Masked: true},
+		{Name: "SECRET", Value: "secret123", Masked: true},
+	})
+	m.toolsTable = newTable(getToolsTableConfig(), nil, false)
+	m.tools = []loader.Tool{
+		{Name: "go", Version: "1.25.1", SourcePath: "/p/mise.toml"},
+		{Name: "node", Version: "22.1.0", SourcePath: "/p/.tool-versions"},
+	}
+	m.filters[focusTools] = newTableFilter("")
+	m.filters[focusEnvVars] = newTableFilter("")
+
+	m.filters[focusTools].input.SetValue("node")
+	m = m.applyToolFilter(true)
+	tool, ok := m.selectedToolRow()
+	if !ok || tool.Name != "node" {
+		t.Errorf("selectedToolRow() = %q, %v, want node", tool.Name, ok)
+	}
+
+	m.filters[focusEnvVars].input.SetValue("secret")
+	m = m.applyEnvVarFilter(true)
+	m = showSelectedEnvVar(m)
+	for _, ev := range m.envVars {
+		if wantMasked := ev.Name != "SECRET"; ev.Masked != wantMasked {
+			t.Errorf("%s masked = %v, want %v", ev.Name, ev.Masked, wantMasked)
+		}
+	}
+	// The filter stays applied after unmasking
+	if len(m.filteredEnvVars) != 1 {
+		t.Errorf("filteredEnvVars has %d rows, want 1", len(m.filteredEnvVars))
+	}
+}
+
+func TestHandleTaskOutput_StreamFilter(t *testing.T) {
+	m := model{
+		styles:   newStyles(),
+		search:   newOutputSearch(),
+		viewport: viewport.New(viewport.WithWidth(80), viewport.WithHeight(10)),
+	}
+	at := time.Date(2025, 1, 1, 9, 30, 15, 0, time.UTC)
+	m = m.handleTaskOutput(taskOutputMsg{line: "compiling", stream: streamStdout, at: at})
+	m = m.handleTaskOutput(taskOutputMsg{line: "warning: unused", stream: streamStderr, at: at})
+	m = m.handleTaskOutput(taskOutputMsg{line: "done", stream: streamStdout, at: at})
+
+	tests := []struct {
+		streams streamFilter
+		want    []string
+	}{
+		{streams: showAllStreams, want: []string{"compiling", "warning: unused", "done"}},
+		{streams: showStdoutOnly, want: []string{"compiling", "done"}},
+		{streams: showStderrOnly, want: []string{"warning: unused"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.streams.String(), func(t *testing.T) {
+			m.streams = tt.streams
+			if got := outputTexts(m.visibleOutput()); !slices.Equal(got, tt.want) {
+				t.Errorf("visibleOutput() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// stderr is styled, and timestamps are prefixed once enabled
+	if m.output[1].display == m.output[1].text {
+		t.Error("stderr line is not styled")
+	}
+	m = m.toggleTimestamps()
+	if !strings.Contains(m.output[0].display, "09:30:15.000") {
+		t.Errorf("display = %q, want timestamp prefix", m.output[0].display)
+	}
+}
+
+func TestPreviousRun(t *testing.T) {
+	runs := []state.Run{
+		{Task: "test", Duration: time.Second},
+		{Task: "test", Duration: 2 * time.Second},
+		{Task: "lint", Duration: 3 * time.Second},
+		{Task: "test"}, // recorded before stats were kept
+	}
+
+	run, ok := previousRun(runs, "test")
+	if !ok || run.Duration != 2*time.Second {
+		t.Errorf("previousRun(test) = %v, %v, want the 2s run", run.Duration, ok)
+	}
+	if _, ok := previousRun(runs, "build"); ok {
+		t.Error("previousRun(build) found a run, want none")
+	}
+}
+
+func TestBackendPicker(t *testing.T) {
+	m := createTestModel(nil)
+	m.pickerState = pickerSelectTool
+	m.toolList = list.New([]list.Item{
+		toolItem{name: "jq", backend: "aqua:jqlang/jq", backends: []string{"aqua:jqlang/jq", "ubi:jqlang/jq"}},
+	}, list.NewDefaultDelegate(), 80, 20)
+
+	enter := tea.KeyPressMsg{Code: tea.KeyEnter}
+	m, _ = m.handlePickerKeys(enter)
+	if m.pickerState != pickerSelectBackend || len(m.backendList.Items()) != 2 {
+		t.Fatalf("pickerState = %v, want the backends of jq", m.pickerState)
+	}
+
+	// Another backend is loaded and installed as backend:tool
+	m.backendList.Select(1)
+	m, cmd := m.handlePickerKeys(enter)
+	if m.pickerState != pickerLoadingVersions || m.selectedTool != "ubi:jqlang/jq" || cmd == nil {
+		t.Fatalf("pickerState = %v tool %q, want the versions of ubi:jqlang/jq loading", m.pickerState, m.selectedTool)
+	}
+
+	// Esc from the versions goes back to the backends, the default keeps the plain name
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "ubi:jqlang/jq", Versions: []string{"1.7.1"}})
+	m, _ = m.handlePickerKeys(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.pickerState != pickerSelectBackend {
+		t.Fatalf("pickerState = %v, want the backend list", m.pickerState)
+	}
+	m.backendList.Select(0)
+	m, _ = m.handlePickerKeys(enter)
+	if m.selectedTool != "jq" {
+		t.Errorf("selectedTool = %q, want jq for the default backend", m.selectedTool)
+	}
+}
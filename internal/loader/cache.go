@@ -0,0 +1,192 @@
+package loader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// How long cached output is fresh. Stale output is still served, but
+// refreshed in the background.
+const (
+	RegistryTTL = 24 * time.Hour
+	VersionsTTL = time.Hour
+)
+
+// cacheDirPerm is the permission of the cache directory, entries are only readable by the user.
+const cacheDirPerm = 0o700
+
+// cacheMaxAge is how long an entry is kept before Prune removes it.
+const cacheMaxAge = 30 * 24 * time.Hour
+
+// Cache keeps the output of slow mise commands on disk, keyed by the command
+// and the mise version that ran it. A nil Cache runs every command.
+type Cache struct {
+	dir         string
+	miseVersion string
+	bypass      bool             // run commands even when their output is cached
+	now         func() time.Time // overridden in tests
+}
+
+// cacheEntry is the file cached output is stored in.
+type cacheEntry struct {
+	Args        []string  `json:"args"`
+	MiseVersion string    `json:"mise_version"`
+	StoredAt    time.Time `json:"stored_at"`
+	Output      []byte    `json:"output"`
+}
+
+// DefaultCacheDir returns the default cache directory,
+// $XDG_CACHE_HOME/prep or ~/.cache/prep.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "prep"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".cache", "prep"), nil
+}
+
+// NewCache returns a cache in dir for the output of miseVersion. An empty dir
+// or an unknown mise version returns nil.
+func NewCache(dir, miseVersion string) *Cache {
+	if dir == "" || miseVersion == "" {
+		return nil
+	}
+	return &Cache{dir: dir, miseVersion: miseVersion, now: time.Now}
+}
+
+// Bypass returns a copy of c that runs commands and caches their output
+// without reading it, to refresh what c has cached.
+func (c *Cache) Bypass() *Cache {
+	if c == nil {
+		return nil
+	}
+	bypass := *c
+	bypass.bypass = true
+	return &bypass
+}
+
+// run returns the cached output of args, or runs them and caches their output.
+// It reports whether the output is older than ttl and should be refreshed.
+func (c *Cache) run(
+	ctx context.Context, runner CommandRunner, ttl time.Duration, args ...string,
+) ([]byte, bool, error) {
+	if c == nil {
+		output, err := runner.Run(ctx, args...)
+		return output, false, err
+	}
+	if !c.bypass {
+		if entry, ok := c.read(args); ok {
+			return entry.Output, c.now().Sub(entry.StoredAt) > ttl, nil
+		}
+	}
+	output, err := runner.Run(ctx, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	// The cache only saves time, output that can't be stored is run again next time
+	_ = c.write(args, output)
+	return output, false, nil
+}
+
+// path returns the file the output of args is cached in.
+func (c *Cache) path(args []string) string {
+	sum := sha256.Sum256([]byte(c.miseVersion + "\x00" + strings.Join(args, "\x00")))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// read returns the cached output of args, if it was cached by the same mise version.
+func (c *Cache) read(args []string) (cacheEntry, bool) {
+	data, err := os.ReadFile(c.path(args))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.MiseVersion != c.miseVersion {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// write caches the output of args, replacing the file atomically.
+func (c *Cache) write(args []string, output []byte) error {
+	data, err := json.Marshal(cacheEntry{
+		Args: args, MiseVersion: c.miseVersion, StoredAt: c.now(), Output: output,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	if err := os.MkdirAll(c.dir, cacheDirPerm); err != nil {
+		return fmt.Errorf("creating %s: %w", c.dir, err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), c.path(args))
+}
+
+// Prune removes the entries older than cacheMaxAge or cached by another mise
+// version, and temporary files left behind by interrupted writes.
+func (c *Cache) Prune() error {
+	if c == nil {
+		return nil
+	}
+	files, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", c.dir, err)
+	}
+	var errs []error
+	for _, file := range files {
+		if file.IsDir() || !c.expired(file) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, file.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expired reports whether Prune should remove file from the cache directory.
+func (c *Cache) expired(file fs.DirEntry) bool {
+	switch filepath.Ext(file.Name()) {
+	case ".tmp":
+		info, err := file.Info()
+		return err == nil && c.now().Sub(info.ModTime()) > cacheMaxAge
+	case ".json":
+		data, err := os.ReadFile(filepath.Join(c.dir, file.Name()))
+		if err != nil {
+			return false
+		}
+		var entry cacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return true
+		}
+		return entry.MiseVersion != c.miseVersion || c.now().Sub(entry.StoredAt) > cacheMaxAge
+	default:
+		return false
+	}
+}
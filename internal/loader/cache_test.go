@@ -0,0 +1,123 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingRunner returns output and counts the commands it runs.
+type countingRunner struct {
+	output string
+	err    error
+	runs   int
+}
+
+func (r *countingRunner) Run(_ context.Context, _ ...string) ([]byte, error) {
+	r.runs++
+	return []byte(r.output), r.err
+}
+
+func TestCacheRun(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewCache(dir, "2026.10.0")
+	cache.now = func() time.Time { return now }
+	runner := &countingRunner{output: "node  core:node"}
+
+	output, stale, err := cache.run(ctx, runner, time.Hour, "registry")
+	if err != nil || string(output) != "node  core:node" || stale || runner.runs != 1 {
+		t.Fatalf("run() = %q, %v, %v after %d runs, want the output of one run", output, stale, err, runner.runs)
+	}
+
+	// Cached output is served without running mise, and is stale after the ttl
+	runner.output = "node  core:node\ngo  core:go"
+	if _, stale, _ = cache.run(ctx, runner, time.Hour, "registry"); stale || runner.runs != 1 {
+		t.Errorf("fresh entry: stale = %v after %d runs, want the cached output", stale, runner.runs)
+	}
+	now = now.Add(2 * time.Hour)
+	output, stale, _ = cache.run(ctx, runner, time.Hour, "registry")
+	if !stale || string(output) != "node  core:node" || runner.runs != 1 {
+		t.Errorf("old entry: run() = %q, stale %v after %d runs, want the stale cached output", output, stale, runner.runs)
+	}
+
+	// Bypassing refreshes the entry
+	output, stale, _ = cache.Bypass().run(ctx, runner, time.Hour, "registry")
+	if stale || runner.runs != 2 || string(output) != runner.output {
+		t.Errorf("bypass: run() = %q, stale %v after %d runs, want a new run", output, stale, runner.runs)
+	}
+	if output, _, _ = cache.run(ctx, runner, time.Hour, "registry"); string(output) != runner.output {
+		t.Errorf("run() = %q after the refresh, want the refreshed output", output)
+	}
+
+	// Other commands and mise versions have their own entries
+	if _, _, _ = cache.run(ctx, runner, time.Hour, "ls-remote", "node"); runner.runs != 3 {
+		t.Errorf("another command used the cached output, %d runs", runner.runs)
+	}
+	if _, _, _ = NewCache(dir, "2026.11.0").run(ctx, runner, time.Hour, "registry"); runner.runs != 4 {
+		t.Errorf("another mise version used the cached output, %d runs", runner.runs)
+	}
+
+	// Failures aren't cached
+	runner.err = errors.New("offline")
+	if _, _, err = cache.run(ctx, runner, time.Hour, "ls-remote", "go"); err == nil {
+		t.Error("run() error = nil, want the runner error")
+	}
+	runner.err = nil
+	if _, _, _ = cache.run(ctx, runner, time.Hour, "ls-remote", "go"); runner.runs != 6 {
+		t.Errorf("a failed run was cached, %d runs", runner.runs)
+	}
+}
+
+func TestCacheRun_Nil(t *testing.T) {
+	var cache *Cache
+	runner := &countingRunner{output: "22.1.0"}
+	for range 2 {
+		if _, stale, _ := cache.Bypass().run(context.Background(), runner, time.Hour, "ls-remote", "node"); stale {
+			t.Error("a nil cache reported stale output")
+		}
+	}
+	if runner.runs != 2 || NewCache("", "2026.10.0") != nil || NewCache(t.TempDir(), "") != nil {
+		t.Errorf("a nil cache ran %d times, want every time", runner.runs)
+	}
+}
+
+func TestCachePrune(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	runner := &countingRunner{output: "node  core:node"}
+
+	old := NewCache(dir, "2026.9.0")
+	old.now = func() time.Time { return now }
+	_, _, _ = old.run(ctx, runner, time.Hour, "registry")
+	cache := NewCache(dir, "2026.10.0")
+	cache.now = func() time.Time { return now.Add(-cacheMaxAge - time.Hour) }
+	_, _, _ = cache.run(ctx, runner, time.Hour, "ls-remote", "node")
+	cache.now = func() time.Time { return now }
+	_, _, _ = cache.run(ctx, runner, time.Hour, "registry")
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "entry-1.tmp"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(dir, "entry-1.tmp"), now, now.Add(-cacheMaxAge-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cache.Prune(); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != filepath.Base(cache.path([]string{"registry"})) {
+		t.Errorf("Prune() kept %v, want only the fresh entry of this mise version", files)
+	}
+	if err := NewCache(filepath.Join(dir, "missing"), "2026.10.0").Prune(); err != nil {
+		t.Errorf("Prune() of a missing directory error = %v, want nil", err)
+	}
+}
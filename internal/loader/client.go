@@ -0,0 +1,59 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/shlex"
+)
+
+// DefaultBinary is the mise binary run when none is configured.
+const DefaultBinary = "mise"
+
+// ErrInvalidMiseFlags is returned when global mise flags can't be split into arguments.
+var ErrInvalidMiseFlags = errors.New("invalid mise flags")
+
+// Client runs mise with a configured binary and global flags. Loader
+// functions and task runs pass it the mise arguments, like tasks --json,
+// and it runs the binary followed by the flags and those arguments.
+type Client struct {
+	binary string
+	flags  []string
+	runner CommandRunner
+}
+
+// NewClient returns a client running mise through runner. An empty binary runs mise from the PATH.
+func NewClient(runner CommandRunner, binary string, flags []string) *Client {
+	if binary == "" {
+		binary = DefaultBinary
+	}
+	return &Client{binary: binary, flags: slices.Clone(flags), runner: runner}
+}
+
+// ParseFlags splits global mise flags written as on a command line, like --cd ../api --verbose.
+func ParseFlags(flags string) ([]string, error) {
+	args, err := shlex.Split(flags)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrInvalidMiseFlags, flags, err)
+	}
+	return args, nil
+}
+
+// CommandLine returns the command line that runs mise with args: the binary,
+// the global flags of c, then args. A nil Client runs mise from the PATH.
+func (c *Client) CommandLine(args []string) []string {
+	if c == nil {
+		return append([]string{DefaultBinary}, args...)
+	}
+	line := make([]string, 0, 1+len(c.flags)+len(args))
+	line = append(line, c.binary)
+	line = append(line, c.flags...)
+	return append(line, args...)
+}
+
+// Run runs mise with args and returns its output.
+func (c *Client) Run(ctx context.Context, args ...string) ([]byte, error) {
+	return c.runner.Run(ctx, c.CommandLine(args)...)
+}
@@ -0,0 +1,46 @@
+package loader_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestClient(t *testing.T) {
+	var ran []string
+	runner := &CommandRunnerMock{
+		RunFunc: func(_ context.Context, args ...string) ([]byte, error) {
+			ran = args
+			return nil, nil
+		},
+	}
+	flags, err := loader.ParseFlags(`--cd "../my api" --verbose`)
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	client := loader.NewClient(runner, "/opt/mise/bin/mise", flags)
+
+	if _, err := client.Run(context.Background(), "ls", "--json"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{"/opt/mise/bin/mise", "--cd", "../my api", "--verbose", "ls", "--json"}
+	if !slices.Equal(ran, want) {
+		t.Errorf("ran %q, want %q", ran, want)
+	}
+
+	// A nil client runs mise from the PATH without flags
+	var none *loader.Client
+	if got := none.CommandLine([]string{"run", "test"}); !slices.Equal(got, []string{"mise", "run", "test"}) {
+		t.Errorf("nil CommandLine() = %q", got)
+	}
+	if got := loader.NewClient(runner, "", nil).CommandLine([]string{"run"}); got[0] != "mise" {
+		t.Errorf("CommandLine() without a binary = %q, want mise from the PATH", got)
+	}
+
+	if _, err := loader.ParseFlags(`--cd "unterminated`); !errors.Is(err, loader.ErrInvalidMiseFlags) {
+		t.Errorf("ParseFlags() error = %v, want ErrInvalidMiseFlags", err)
+	}
+}
@@ -5,13 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path/filepath"
 	"strings"
 
 	tea "charm.land/bubbletea/v2"
 )
 
-// minRegistryFields is the minimum number of fields expected in a registry line.
-const minRegistryFields = 2
+const (
+	// minRegistryFields is the minimum number of fields expected in a registry line.
+	minRegistryFields = 2
+	// versionAliasFields is the number of fields in a line of mise alias ls.
+	versionAliasFields = 3
+)
 
 // CommandRunner runs commands.
 type CommandRunner interface {
@@ -26,6 +32,35 @@ type Task struct {
 	Source      string   `json:"source"`
 	Hide        bool     `json:"hide"`
 	Run         []string `json:"run"`
+	Sources     []string `json:"sources"`      // globs of the files the task builds from
+	Dir         string   `json:"dir"`          // directory the task runs in, if set
+	Depends     []string `json:"depends"`      // tasks that run before it, optionally with args
+	DependsPost []string `json:"depends_post"` // tasks that run after it
+	Env         TaskEnv  `json:"env"`          // environment the task sets
+}
+
+// TaskEnv is the environment a task sets, by variable name. Values that
+// aren't strings, like numbers and booleans, are kept as written.
+type TaskEnv map[string]string
+
+// UnmarshalJSON implements json.Unmarshaler. An env that isn't an object is
+// left empty rather than failing to load every task.
+func (e *TaskEnv) UnmarshalJSON(data []byte) error {
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		*e = nil
+		return nil //nolint:nilerr // the env is only shown, tasks still load without it
+	}
+	env := make(TaskEnv, len(values))
+	for name, value := range values {
+		if s, ok := value.(string); ok {
+			env[name] = s
+			continue
+		}
+		env[name] = fmt.Sprint(value)
+	}
+	*e = env
+	return nil
 }
 
 // Tool represents a mise tool (parsed from mise ls --json).
@@ -45,7 +80,8 @@ type miseToolEntry struct {
 		Type string `json:"type"`
 		Path string `json:"path"`
 	} `json:"source"`
-	Active bool `json:"active"`
+	Active    bool `json:"active"`
+	Installed bool `json:"installed"`
 }
 
 // EnvVar represents a mise environment variable.
@@ -92,21 +128,27 @@ type ConfigFilesLoadedMsg struct {
 
 // RegistryTool represents a tool from the mise registry.
 type RegistryTool struct {
-	Name    string
-	Backend string
+	Name     string
+	Backend  string   // backend mise installs the tool with by default
+	Backends []string // every backend the tool can be installed with, the default first
 }
 
 // RegistryLoadedMsg is sent when mise registry data is loaded.
 type RegistryLoadedMsg struct {
 	Tools []RegistryTool
+	Stale bool // loaded from a cache entry that is due for a refresh
 	Err   error
 }
 
 // VersionsLoadedMsg is sent when versions are loaded for a tool.
 type VersionsLoadedMsg struct {
-	Tool     string
-	Versions []string
-	Err      error
+	Tool      string
+	Versions  []string // newest first
+	Installed []string // versions of the tool that are installed
+	Requested string   // version the active config requests, like 22
+	Active    string   // version in use, like 22.1.0
+	Stale     bool     // versions from a cache entry that is due for a refresh
+	Err       error
 }
 
 // ToolInstalledMsg is sent when tool installation completes.
@@ -123,28 +165,60 @@ type ToolRemovedMsg struct {
 	Err     error
 }
 
-// ReloadMiseData returns commands to reload all mise data.
-func ReloadMiseData(runner CommandRunner) tea.Cmd {
-	ctx := context.Background()
-	return tea.Batch(
-		LoadMiseTasks(ctx, runner),
-		LoadMiseTools(ctx, runner),
-		LoadMiseEnvVars(ctx, runner),
-	)
+// ToolDetail describes a tool in depth, from mise tool, mise where and mise ls.
+type ToolDetail struct {
+	Name              string
+	Backend           string // backend the tool is installed with, like aqua:nodejs/node
+	Description       string // description from the registry
+	InstallPath       string // install directory of the active version, empty if it isn't installed
+	DiskUsage         int64  // bytes used by the install directory
+	InstalledVersions []string
+	ActiveVersions    []string
+	Aliases           []VersionAlias // version aliases like lts
+	RequestedBy       []ToolRequest  // config files requesting the tool, the active request first
+}
+
+// VersionAlias is a version alias of a tool, like lts for node.
+type VersionAlias struct {
+	Alias   string
+	Version string
+}
+
+// ToolRequest is a version of a tool requested by a config file.
+type ToolRequest struct {
+	Version          string
+	RequestedVersion string
+	SourcePath       string
+	Active           bool
+}
+
+// miseToolInfo is the output of mise tool --json.
+type miseToolInfo struct {
+	Backend           string   `json:"backend"`
+	Description       string   `json:"description"`
+	InstalledVersions []string `json:"installed_versions"`
+	ActiveVersions    []string `json:"active_versions"`
+}
+
+// ToolDetailLoadedMsg is sent when the details of a tool are loaded.
+type ToolDetailLoadedMsg struct {
+	Tool   string
+	Detail ToolDetail
+	Err    error
 }
 
 // loadJSON is a generic loader that runs a command and unmarshals JSON.
 func loadJSON[T any](
 	ctx context.Context,
-	runner CommandRunner,
+	mise *Client,
 	args []string,
 	transform func(T) tea.Msg,
 	errMsg func(error) tea.Msg,
 ) tea.Cmd {
 	return func() tea.Msg {
-		output, err := runner.Run(ctx, args...)
+		output, err := mise.Run(ctx, args...)
 		if err != nil {
-			return errMsg(fmt.Errorf("failed to execute %s: %w", args[0], err))
+			return errMsg(fmt.Errorf("failed to execute mise %s: %w", args[0], err))
 		}
 
 		var data T
@@ -158,16 +232,21 @@ func loadJSON[T any](
 }
 
 // LoadMiseTasks returns a Cmd that loads tasks asynchronously.
-func LoadMiseTasks(ctx context.Context, runner CommandRunner) tea.Cmd {
-	return loadJSON(ctx, runner, []string{"mise", "tasks", "--json"},
+// If includeHidden is true, tasks marked with hide = true are included.
+func LoadMiseTasks(ctx context.Context, mise *Client, includeHidden bool) tea.Cmd {
+	args := []string{"tasks", "--json"}
+	if includeHidden {
+		args = append(args, "--hidden")
+	}
+	return loadJSON(ctx, mise, args,
 		func(tasks []Task) tea.Msg { return TasksLoadedMsg{Tasks: tasks} },
 		func(err error) tea.Msg { return TasksLoadedMsg{Err: err} },
 	)
 }
 
 // LoadMiseTools returns a Cmd that loads tools asynchronously.
-func LoadMiseTools(ctx context.Context, runner CommandRunner) tea.Cmd {
-	return loadJSON(ctx, runner, []string{"mise", "ls", "--json"},
+func LoadMiseTools(ctx context.Context, mise *Client) tea.Cmd {
+	return loadJSON(ctx, mise, []string{"ls", "--json"},
 		func(rawTools map[string][]miseToolEntry) tea.Msg {
 			var tools []Tool
 			for name, entries := range rawTools {
@@ -194,8 +273,8 @@ func LoadMiseTools(ctx context.Context, runner CommandRunner) tea.Cmd {
 }
 
 // LoadMiseEnvVars returns a Cmd that loads environment variables asynchronously.
-func LoadMiseEnvVars(ctx context.Context, runner CommandRunner) tea.Cmd {
-	return loadJSON(ctx, runner, []string{"mise", "env", "--json"},
+func LoadMiseEnvVars(ctx context.Context, mise *Client) tea.Cmd {
+	return loadJSON(ctx, mise, []string{"env", "--json"},
 		func(rawEnvVars map[string]string) tea.Msg {
 			var envVars []EnvVar
 			for name, value := range rawEnvVars {
@@ -212,9 +291,9 @@ func LoadMiseEnvVars(ctx context.Context, runner CommandRunner) tea.Cmd {
 }
 
 // LoadMiseVersion returns a Cmd that loads the mise version asynchronously.
-func LoadMiseVersion(ctx context.Context, runner CommandRunner) tea.Cmd {
+func LoadMiseVersion(ctx context.Context, mise *Client) tea.Cmd {
 	return func() tea.Msg {
-		output, err := runner.Run(ctx, "mise", "--version")
+		output, err := mise.Run(ctx, "--version")
 		if err != nil {
 			return MiseVersionMsg{Err: err}
 		}
@@ -229,8 +308,8 @@ func LoadMiseVersion(ctx context.Context, runner CommandRunner) tea.Cmd {
 }
 
 // LoadMiseConfigFiles returns a Cmd that loads config file paths from mise.
-func LoadMiseConfigFiles(ctx context.Context, runner CommandRunner) tea.Cmd {
-	return loadJSON(ctx, runner, []string{"mise", "cfg", "--json"},
+func LoadMiseConfigFiles(ctx context.Context, mise *Client) tea.Cmd {
+	return loadJSON(ctx, mise, []string{"cfg", "--json"},
 		func(configs []miseConfigEntry) tea.Msg {
 			paths := make([]string, len(configs))
 			for i, c := range configs {
@@ -243,9 +322,9 @@ func LoadMiseConfigFiles(ctx context.Context, runner CommandRunner) tea.Cmd {
 }
 
 // LoadMiseRegistry returns a Cmd that loads available tools from mise registry.
-func LoadMiseRegistry(ctx context.Context, runner CommandRunner) tea.Cmd {
+func LoadMiseRegistry(ctx context.Context, mise *Client, cache *Cache) tea.Cmd {
 	return func() tea.Msg {
-		output, err := runner.Run(ctx, "mise", "registry")
+		output, stale, err := cache.run(ctx, mise, RegistryTTL, "registry")
 		if err != nil {
 			return RegistryLoadedMsg{Err: fmt.Errorf("failed to load registry: %w", err)}
 		}
@@ -261,19 +340,21 @@ func LoadMiseRegistry(ctx context.Context, runner CommandRunner) tea.Cmd {
 			fields := strings.Fields(line)
 			if len(fields) >= minRegistryFields {
 				tools = append(tools, RegistryTool{
-					Name:    fields[0],
-					Backend: fields[1],
+					Name:     fields[0],
+					Backend:  fields[1],
+					Backends: fields[1:],
 				})
 			}
 		}
-		return RegistryLoadedMsg{Tools: tools}
+		return RegistryLoadedMsg{Tools: tools, Stale: stale}
 	}
 }
 
-// LoadToolVersions returns a Cmd that loads available versions for a tool.
-func LoadToolVersions(ctx context.Context, runner CommandRunner, tool string) tea.Cmd {
+// LoadToolVersions returns a Cmd that loads available versions for a tool,
+// and which of them it has installed and requests when mise ls can tell.
+func LoadToolVersions(ctx context.Context, mise *Client, cache *Cache, tool string) tea.Cmd {
 	return func() tea.Msg {
-		output, err := runner.Run(ctx, "mise", "ls-remote", tool)
+		output, stale, err := cache.run(ctx, mise, VersionsTTL, "ls-remote", tool)
 		if err != nil {
 			return VersionsLoadedMsg{Tool: tool, Err: fmt.Errorf("failed to load versions: %w", err)}
 		}
@@ -293,20 +374,44 @@ func LoadToolVersions(ctx context.Context, runner CommandRunner, tool string) te
 			versions[i], versions[j] = versions[j], versions[i]
 		}
 
-		return VersionsLoadedMsg{Tool: tool, Versions: versions}
+		msg := VersionsLoadedMsg{Tool: tool, Versions: versions, Stale: stale}
+		if ls, err := mise.Run(ctx, "ls", "--json"); err == nil {
+			msg.Installed, msg.Requested, msg.Active = parseToolUsage(ls, tool)
+		}
+		return msg
+	}
+}
+
+// parseToolUsage returns the installed versions of tool in mise ls --json
+// output, and the requested and resolved version of its active entry.
+func parseToolUsage(output []byte, tool string) ([]string, string, string) {
+	var rawTools map[string][]miseToolEntry
+	if err := json.Unmarshal(output, &rawTools); err != nil {
+		return nil, "", ""
 	}
+	var installed []string
+	var requested, active string
+	for _, entry := range rawTools[tool] {
+		if entry.Installed {
+			installed = append(installed, entry.Version)
+		}
+		if entry.Active {
+			requested, active = entry.RequestedVersion, entry.Version
+		}
+	}
+	return installed, requested, active
 }
 
 // InstallTool returns a Cmd that installs a tool at a specific version.
 // If configPath is provided, the tool will be added to that specific config file.
-func InstallTool(ctx context.Context, runner CommandRunner, tool, version, configPath string) tea.Cmd {
+func InstallTool(ctx context.Context, mise *Client, tool, version, configPath string) tea.Cmd {
 	return func() tea.Msg {
-		args := []string{"mise", "use"}
+		args := []string{"use"}
 		if configPath != "" {
 			args = append(args, "--path", configPath)
 		}
 		args = append(args, tool+"@"+version)
-		_, err := runner.Run(ctx, args...)
+		_, err := mise.Run(ctx, args...)
 		if err != nil {
 			return ToolInstalledMsg{Tool: tool, Version: version, Err: fmt.Errorf("failed to install: %w", err)}
 		}
@@ -314,12 +419,106 @@ func InstallTool(ctx context.Context, runner CommandRunner, tool, version, confi
 	}
 }
 
-func RemoveTool(ctx context.Context, runner CommandRunner, tool, version string) tea.Cmd {
+func RemoveTool(ctx context.Context, mise *Client, tool, version string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := runner.Run(ctx, "mise", "unuse", tool+"@"+version)
+		_, err := mise.Run(ctx, "unuse", tool+"@"+version)
 		if err != nil {
 			return ToolRemovedMsg{Tool: tool, Version: version, Err: fmt.Errorf("failed to remove: %w", err)}
 		}
 		return ToolRemovedMsg{Tool: tool, Version: version}
 	}
 }
+
+// LoadToolDetail returns a Cmd that loads the details of an installed tool.
+// Only mise tool is required to succeed, the install path, aliases and
+// requests are left out when mise can't tell them.
+func LoadToolDetail(ctx context.Context, mise *Client, tool string) tea.Cmd {
+	return func() tea.Msg {
+		output, err := mise.Run(ctx, "tool", tool, "--json")
+		if err != nil {
+			return ToolDetailLoadedMsg{Tool: tool, Err: fmt.Errorf("failed to load tool: %w", err)}
+		}
+		var info miseToolInfo
+		if err := json.Unmarshal(output, &info); err != nil {
+			return ToolDetailLoadedMsg{Tool: tool, Err: fmt.Errorf("failed to parse JSON: %w", err)}
+		}
+
+		detail := ToolDetail{
+			Name:              tool,
+			Backend:           info.Backend,
+			Description:       info.Description,
+			InstalledVersions: info.InstalledVersions,
+			ActiveVersions:    info.ActiveVersions,
+		}
+		if where, err := mise.Run(ctx, "where", tool); err == nil {
+			detail.InstallPath = strings.TrimSpace(string(where))
+			detail.DiskUsage = diskUsage(detail.InstallPath)
+		}
+		if aliases, err := mise.Run(ctx, "alias", "ls", tool); err == nil {
+			detail.Aliases = parseVersionAliases(string(aliases))
+		}
+		if ls, err := mise.Run(ctx, "ls", "--json"); err == nil {
+			detail.RequestedBy = parseToolRequests(ls, tool)
+		}
+		return ToolDetailLoadedMsg{Tool: tool, Detail: detail}
+	}
+}
+
+// parseVersionAliases parses mise alias ls, which prints a tool, alias and version per line.
+func parseVersionAliases(output string) []VersionAlias {
+	var aliases []VersionAlias
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != versionAliasFields {
+			continue
+		}
+		aliases = append(aliases, VersionAlias{Alias: fields[1], Version: fields[2]})
+	}
+	return aliases
+}
+
+// parseToolRequests returns the requests of tool in mise ls --json output,
+// the active request first.
+func parseToolRequests(output []byte, tool string) []ToolRequest {
+	var rawTools map[string][]miseToolEntry
+	if err := json.Unmarshal(output, &rawTools); err != nil {
+		return nil
+	}
+	var requests []ToolRequest
+	for _, entry := range rawTools[tool] {
+		if entry.Source == nil {
+			continue
+		}
+		request := ToolRequest{
+			Version:          entry.Version,
+			RequestedVersion: entry.RequestedVersion,
+			SourcePath:       entry.Source.Path,
+			Active:           entry.Active,
+		}
+		if entry.Active {
+			requests = append([]ToolRequest{request}, requests...)
+		} else {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// diskUsage returns the bytes used by the files below dir. Files that can't
+// be read don't count.
+func diskUsage(dir string) int64 {
+	if dir == "" {
+		return 0
+	}
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil //nolint:nilerr // unreadable entries are skipped
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -3,6 +3,11 @@ package loader_test
 import (
 	"context"
 	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/rshep3087/prep/internal/loader"
@@ -58,6 +63,16 @@ python  core:python
 			wantTools:  1,
 			checkFirst: &loader.RegistryTool{Name: "python", Backend: "core:python"},
 		},
+		{
+			name:      "keeps additional backends",
+			output:    "jq    aqua:jqlang/jq ubi:jqlang/jq asdf:mise-plugins/asdf-jq",
+			wantTools: 1,
+			checkFirst: &loader.RegistryTool{
+				Name:     "jq",
+				Backend:  "aqua:jqlang/jq",
+				Backends: []string{"aqua:jqlang/jq", "ubi:jqlang/jq", "asdf:mise-plugins/asdf-jq"},
+			},
+		},
 		{
 			name:    "handles runner error",
 			runErr:  errors.New("command failed"),
@@ -77,7 +92,7 @@ python  core:python
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadMiseRegistry(context.Background(), runner)
+			cmd := loader.LoadMiseRegistry(context.Background(), loader.NewClient(runner, "", nil), nil)
 			msg := cmd()
 
 			loaded, ok := msg.(loader.RegistryLoadedMsg)
@@ -119,6 +134,9 @@ func assertFirstRegistryTool(t *testing.T, tools []loader.RegistryTool, want *lo
 	if tools[0].Backend != want.Backend {
 		t.Errorf("first tool backend = %q, want %q", tools[0].Backend, want.Backend)
 	}
+	if want.Backends != nil && !slices.Equal(tools[0].Backends, want.Backends) {
+		t.Errorf("first tool backends = %q, want %q", tools[0].Backends, want.Backends)
+	}
 }
 
 func TestLoadToolVersions(t *testing.T) {
@@ -177,7 +195,7 @@ func TestLoadToolVersions(t *testing.T) {
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadToolVersions(context.Background(), runner, tt.tool)
+			cmd := loader.LoadToolVersions(context.Background(), loader.NewClient(runner, "", nil), nil, tt.tool)
 			msg := cmd()
 
 			loaded, ok := msg.(loader.VersionsLoadedMsg)
@@ -303,7 +321,7 @@ func TestLoadMiseTools(t *testing.T) {
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadMiseTools(context.Background(), runner)
+			cmd := loader.LoadMiseTools(context.Background(), loader.NewClient(runner, "", nil))
 			msg := cmd()
 
 			loaded, ok := msg.(loader.ToolsLoadedMsg)
@@ -406,7 +424,7 @@ func TestLoadMiseVersion(t *testing.T) {
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadMiseVersion(context.Background(), runner)
+			cmd := loader.LoadMiseVersion(context.Background(), loader.NewClient(runner, "", nil))
 			msg := cmd()
 
 			loaded, ok := msg.(loader.MiseVersionMsg)
@@ -444,11 +462,16 @@ func TestLoadMiseTasks(t *testing.T) {
 		{
 			name: "parses tasks",
 			output: `[
-				{"name": "build", "aliases": [], "description": "Build the project", "source": "mise.toml", "hide": false, "run": ["go build"]},
-				{"name": "test", "aliases": ["t"], "description": "Run tests", "source": "mise.toml", "hide": false, "run": ["go test ./..."]}
+				{"name": "build", "aliases": [], "description": "Build the project", "source": "mise.toml", "hide": false, "run": ["go build"], "sources": ["**/*.go"], "dir": null},
+				{"name": "test", "aliases": ["t"], "description": "Run tests", "source": "mise.toml", "hide": false, "run": ["go test ./..."], "depends": ["build"], "depends_post": ["clean"], "env": {"GOFLAGS": "-race", "CGO_ENABLED": 0}}
 			]`,
 			wantTasks: 2,
 		},
+		{
+			name:      "loads tasks whose env can't be read",
+			output:    `[{"name": "lint", "env": [["FOO", "1"]]}]`,
+			wantTasks: 1,
+		},
 		{
 			name:      "handles empty tasks",
 			output:    `[]`,
@@ -468,7 +491,7 @@ func TestLoadMiseTasks(t *testing.T) {
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadMiseTasks(context.Background(), runner)
+			cmd := loader.LoadMiseTasks(context.Background(), loader.NewClient(runner, "", nil), false)
 			msg := cmd()
 
 			loaded, ok := msg.(loader.TasksLoadedMsg)
@@ -491,6 +514,59 @@ func TestLoadMiseTasks(t *testing.T) {
 			if len(loaded.Tasks) != tt.wantTasks {
 				t.Errorf("got %d tasks, want %d", len(loaded.Tasks), tt.wantTasks)
 			}
+			for _, task := range loaded.Tasks {
+				if task.Name == "test" && (!slices.Equal(task.Depends, []string{"build"}) ||
+					!slices.Equal(task.DependsPost, []string{"clean"})) {
+					t.Errorf("test depends on %q then %q, want build then clean", task.Depends, task.DependsPost)
+				}
+				if want := (loader.TaskEnv{"GOFLAGS": "-race", "CGO_ENABLED": "0"}); task.Name == "test" &&
+					!maps.Equal(task.Env, want) {
+					t.Errorf("test sets env %v, want %v", task.Env, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMiseTasks_Hidden(t *testing.T) {
+	tests := []struct {
+		name          string
+		includeHidden bool
+		wantArgs      []string
+	}{
+		{
+			name:     "omits hidden flag by default",
+			wantArgs: []string{"mise", "tasks", "--json"},
+		},
+		{
+			name:          "passes hidden flag when requested",
+			includeHidden: true,
+			wantArgs:      []string{"mise", "tasks", "--json", "--hidden"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []string
+			runner := &CommandRunnerMock{
+				RunFunc: func(_ context.Context, args ...string) ([]byte, error) {
+					gotArgs = args
+					return []byte(`[{"name": "secret", "aliases": ["s"], "hide": true}]`), nil
+				},
+			}
+			msg := loader.LoadMiseTasks(context.Background(), loader.NewClient(runner, "", nil), tt.includeHidden)()
+
+			if !slices.Equal(gotArgs, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+
+			loaded, ok := msg.(loader.TasksLoadedMsg)
+			if !ok {
+				t.Fatalf("expected loader.TasksLoadedMsg, got %T", msg)
+			}
+			if len(loaded.Tasks) != 1 || !loaded.Tasks[0].Hide || !slices.Equal(loaded.Tasks[0].Aliases, []string{"s"}) {
+				t.Errorf("unexpected tasks: %+v", loaded.Tasks)
+			}
 		})
 	}
 }
@@ -529,7 +605,7 @@ func TestLoadMiseEnvVars(t *testing.T) {
 					return []byte(tt.output), tt.runErr
 				},
 			}
-			cmd := loader.LoadMiseEnvVars(context.Background(), runner)
+			cmd := loader.LoadMiseEnvVars(context.Background(), loader.NewClient(runner, "", nil))
 			msg := cmd()
 
 			loaded, ok := msg.(loader.EnvVarsLoadedMsg)
@@ -581,3 +657,83 @@ func assertAllEnvVarsMasked(t *testing.T, envVars []loader.EnvVar) {
 		}
 	}
 }
+
+func TestLoadToolDetail(t *testing.T) {
+	installPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(installPath, "node"), make([]byte, 1500), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{
+		"mise tool node --json": `{
+			"backend": "aqua:nodejs/node",
+			"description": "Node.js JavaScript runtime",
+			"installed_versions": ["20.0.0", "22.1.0"],
+			"active_versions": ["22.1.0"]
+		}`,
+		"mise where node":    installPath + "\n",
+		"mise alias ls node": "node  lts      22\nnode  lts-iron 20\n",
+		"mise ls --json": `{
+			"node": [
+				{"version": "20.0.0", "requested_version": "20", "source": {"type": "mise.toml", "path": "/home/user/.config/mise/config.toml"}, "active": false},
+				{"version": "22.1.0", "requested_version": "22", "source": {"type": "mise.toml", "path": "/p/mise.toml"}, "active": true}
+			],
+			"go": [{"version": "1.25.0", "requested_version": "1.25", "source": {"type": "mise.toml", "path": "/p/mise.toml"}, "active": true}]
+		}`,
+	}
+	runner := &CommandRunnerMock{
+		RunFunc: func(_ context.Context, args ...string) ([]byte, error) {
+			out, ok := outputs[strings.Join(args, " ")]
+			if !ok {
+				return nil, errors.New("unexpected command")
+			}
+			return []byte(out), nil
+		},
+	}
+
+	msg := loader.LoadToolDetail(context.Background(), loader.NewClient(runner, "", nil), "node")()
+	loaded, ok := msg.(loader.ToolDetailLoadedMsg)
+	if !ok {
+		t.Fatalf("expected loader.ToolDetailLoadedMsg, got %T", msg)
+	}
+	if loaded.Err != nil {
+		t.Fatalf("unexpected error: %v", loaded.Err)
+	}
+
+	d := loaded.Detail
+	if d.Backend != "aqua:nodejs/node" || d.Description != "Node.js JavaScript runtime" {
+		t.Errorf("backend = %q, description = %q", d.Backend, d.Description)
+	}
+	if d.InstallPath != installPath || d.DiskUsage != 1500 {
+		t.Errorf("install path = %q, disk usage = %d, want %q and 1500", d.InstallPath, d.DiskUsage, installPath)
+	}
+	if !slices.Equal(d.InstalledVersions, []string{"20.0.0", "22.1.0"}) ||
+		!slices.Equal(d.ActiveVersions, []string{"22.1.0"}) {
+		t.Errorf("installed = %q, active = %q", d.InstalledVersions, d.ActiveVersions)
+	}
+	wantAliases := []loader.VersionAlias{{Alias: "lts", Version: "22"}, {Alias: "lts-iron", Version: "20"}}
+	if !slices.Equal(d.Aliases, wantAliases) {
+		t.Errorf("aliases = %+v, want %+v", d.Aliases, wantAliases)
+	}
+	if len(d.RequestedBy) != 2 || !d.RequestedBy[0].Active || d.RequestedBy[0].SourcePath != "/p/mise.toml" {
+		t.Errorf("requested by = %+v, want the active request of /p/mise.toml first", d.RequestedBy)
+	}
+}
+
+func TestLoadToolDetail_NotInstalled(t *testing.T) {
+	runner := &CommandRunnerMock{
+		RunFunc: func(_ context.Context, args ...string) ([]byte, error) {
+			if args[1] == "tool" {
+				return []byte(`{"backend": "core:go", "installed_versions": []}`), nil
+			}
+			return nil, errors.New("go is not installed")
+		},
+	}
+
+	loaded, _ := loader.LoadToolDetail(context.Background(), loader.NewClient(runner, "", nil), "go")().(loader.ToolDetailLoadedMsg)
+	if loaded.Err != nil {
+		t.Fatalf("unexpected error: %v", loaded.Err)
+	}
+	if loaded.Detail.Backend != "core:go" || loaded.Detail.InstallPath != "" || loaded.Detail.RequestedBy != nil {
+		t.Errorf("detail = %+v, want only what mise tool reported", loaded.Detail)
+	}
+}
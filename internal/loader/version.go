@@ -0,0 +1,56 @@
+package loader
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// semverPattern matches a version number with an optional prerelease or
+	// build suffix, like 1.25.4, v2.0.0-rc.1, 1.25rc1 or 3.14.0a1.
+	semverPattern = regexp.MustCompile(`^(v?(\d+)(?:\.(\d+))?)(?:\.(\d+))?(.*)$`)
+	// prereleasePattern matches the markers of versions that aren't releases.
+	prereleasePattern = regexp.MustCompile(
+		`(?i)(alpha|beta|rc|pre|dev|nightly|snapshot|canary|preview|next|master|main|^[.-]?[ab]\d)`)
+)
+
+// Version is a tool version, parsed as semver where it can be.
+type Version struct {
+	Raw        string
+	Semver     bool   // whether Raw starts with a version number
+	Series     string // major.minor of Raw as mise matches it, like 1.25 or v1.25
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string // what follows the version number, like rc.1 of 1.2.0-rc.1
+}
+
+// ParseVersion parses a version printed by mise ls-remote. Versions that
+// don't start with a number, like nightly or temurin-21.0.2, keep only Raw.
+func ParseVersion(raw string) Version {
+	v := Version{Raw: raw}
+	match := semverPattern.FindStringSubmatch(raw)
+	if match == nil {
+		return v
+	}
+	v.Semver = true
+	v.Series = match[1]
+	v.Major, _ = strconv.Atoi(match[2])
+	v.Minor, _ = strconv.Atoi(match[3])
+	v.Patch, _ = strconv.Atoi(match[4])
+	v.Prerelease = strings.TrimLeft(match[5], "-.")
+	// A fourth number, like 1.2.3.4, or build metadata isn't a prerelease
+	if strings.HasPrefix(match[5], "+") {
+		v.Prerelease = ""
+	}
+	return v
+}
+
+// IsPrerelease reports whether v is a prerelease, like an rc, beta or nightly build.
+func (v Version) IsPrerelease() bool {
+	if !v.Semver {
+		return prereleasePattern.MatchString(v.Raw)
+	}
+	return v.Prerelease != "" && prereleasePattern.MatchString(v.Prerelease)
+}
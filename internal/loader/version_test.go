@@ -0,0 +1,79 @@
+package loader_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		raw            string
+		wantSemver     bool
+		wantSeries     string
+		wantMajor      int
+		wantMinor      int
+		wantPatch      int
+		wantPrerelease bool
+	}{
+		{raw: "1.25.4", wantSemver: true, wantSeries: "1.25", wantMajor: 1, wantMinor: 25, wantPatch: 4},
+		{raw: "v2.0.0-rc.1", wantSemver: true, wantSeries: "v2.0", wantMajor: 2, wantPrerelease: true},
+		{raw: "1.26rc1", wantSemver: true, wantSeries: "1.26", wantMajor: 1, wantMinor: 26, wantPrerelease: true},
+		{raw: "3.14.0a1", wantSemver: true, wantSeries: "3.14", wantMajor: 3, wantMinor: 14, wantPrerelease: true},
+		{raw: "22", wantSemver: true, wantSeries: "22", wantMajor: 22},
+		{raw: "1.2.3.4", wantSemver: true, wantSeries: "1.2", wantMajor: 1, wantMinor: 2, wantPatch: 3},
+		{raw: "17.0.2+8", wantSemver: true, wantSeries: "17.0", wantMajor: 17, wantPatch: 2},
+		{raw: "nightly", wantPrerelease: true},
+		{raw: "temurin-21.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			v := loader.ParseVersion(tt.raw)
+			if v.Semver != tt.wantSemver || v.Series != tt.wantSeries {
+				t.Errorf("ParseVersion() semver = %v series %q, want %v %q", v.Semver, v.Series, tt.wantSemver, tt.wantSeries)
+			}
+			if v.Major != tt.wantMajor || v.Minor != tt.wantMinor || v.Patch != tt.wantPatch {
+				t.Errorf("ParseVersion() = %d.%d.%d, want %d.%d.%d",
+					v.Major, v.Minor, v.Patch, tt.wantMajor, tt.wantMinor, tt.wantPatch)
+			}
+			if got := v.IsPrerelease(); got != tt.wantPrerelease {
+				t.Errorf("IsPrerelease() = %v, want %v", got, tt.wantPrerelease)
+			}
+		})
+	}
+}
+
+func TestLoadToolVersions_Usage(t *testing.T) {
+	outputs := map[string]string{
+		"mise ls-remote node": "20.3.0\n22.1.0\n",
+		"mise ls --json": `{"node": [
+			{"version": "20.3.0", "requested_version": "20", "installed": true, "active": false},
+			{"version": "22.1.0", "requested_version": "22", "installed": true, "active": true}
+		]}`,
+	}
+	runner := &CommandRunnerMock{
+		RunFunc: func(_ context.Context, args ...string) ([]byte, error) {
+			out, ok := outputs[strings.Join(args, " ")]
+			if !ok {
+				return nil, errors.New("unexpected command")
+			}
+			return []byte(out), nil
+		},
+	}
+
+	loaded, ok := loader.LoadToolVersions(context.Background(), loader.NewClient(runner, "", nil), nil, "node")().(loader.VersionsLoadedMsg)
+	if !ok || loaded.Err != nil {
+		t.Fatalf("LoadToolVersions() = %+v", loaded)
+	}
+	if want := []string{"20.3.0", "22.1.0"}; !slices.Equal(loaded.Installed, want) {
+		t.Errorf("Installed = %q, want %q", loaded.Installed, want)
+	}
+	if loaded.Requested != "22" || loaded.Active != "22.1.0" {
+		t.Errorf("Requested = %q Active = %q, want 22 and 22.1.0", loaded.Requested, loaded.Active)
+	}
+}
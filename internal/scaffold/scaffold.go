@@ -0,0 +1,311 @@
+// Package scaffold writes new mise task definitions to disk.
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FileTaskDir is the directory, relative to the project root, where mise
+// discovers executable file tasks.
+const FileTaskDir = "mise-tasks"
+
+// File permissions for scaffolded files.
+const (
+	dirPerm        = 0o755
+	configFilePerm = 0o644
+	fileTaskPerm   = 0o755 // file tasks must be executable
+)
+
+// ErrInvalidName is returned when a task name cannot be used.
+var ErrInvalidName = errors.New("invalid task name")
+
+// ErrRunRequired is returned when a task has no run command.
+var ErrRunRequired = errors.New("run command is required")
+
+// ErrTaskExists is returned when the config file already defines the task.
+var ErrTaskExists = errors.New("task already exists")
+
+// taskNamePattern matches the task names mise accepts. Colons separate namespaces.
+var taskNamePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)*$`)
+
+// bareKeyPattern matches TOML keys that don't need quoting.
+var bareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// TaskSpec describes a task to scaffold.
+type TaskSpec struct {
+	Name        string
+	Description string
+	Run         string   // command or multi-line script
+	Depends     []string // names of tasks to run first
+}
+
+// ValidateName checks that name is a valid mise task name.
+func ValidateName(name string) error {
+	if !taskNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// Validate checks that the spec can be written.
+func (s TaskSpec) Validate() error {
+	if err := ValidateName(s.Name); err != nil {
+		return err
+	}
+	if strings.TrimSpace(s.Run) == "" {
+		return ErrRunRequired
+	}
+	return nil
+}
+
+// ParseDepends splits a comma or whitespace separated list of task names.
+func ParseDepends(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// TOMLTable renders the spec as a [tasks.<name>] table.
+func TOMLTable(s TaskSpec) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[tasks.%s]\n", tomlKey(s.Name))
+	if s.Description != "" {
+		fmt.Fprintf(&b, "description = %s\n", tomlString(s.Description))
+	}
+	if len(s.Depends) > 0 {
+		fmt.Fprintf(&b, "depends = %s\n", tomlStringArray(s.Depends))
+	}
+	fmt.Fprintf(&b, "run = %s\n", tomlString(strings.TrimRight(s.Run, "\n")))
+	return b.String()
+}
+
+// FileTask renders the spec as an executable bash script with #MISE header comments.
+func FileTask(s TaskSpec) string {
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	if s.Description != "" {
+		fmt.Fprintf(&b, "#MISE description=%s\n", tomlString(s.Description))
+	}
+	if len(s.Depends) > 0 {
+		fmt.Fprintf(&b, "#MISE depends=%s\n", tomlStringArray(s.Depends))
+	}
+	b.WriteString("\nset -euo pipefail\n\n")
+	b.WriteString(strings.TrimRight(s.Run, "\n"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// FileTaskPath returns where a file task for name lives under root.
+// Namespaced names map to subdirectories, so "db:migrate" becomes mise-tasks/db/migrate.
+func FileTaskPath(root, name string) string {
+	parts := strings.Split(name, ":")
+	return filepath.Join(append([]string{root, FileTaskDir}, parts...)...)
+}
+
+// AppendToConfig appends the spec as a TOML table to the config file at path.
+// It refuses to when the file already defines the task, a second table for it
+// would make the file invalid.
+func AppendToConfig(path string, s TaskSpec) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	if definesTask(string(existing), s.Name) {
+		return fmt.Errorf("%w: %q in %s", ErrTaskExists, s.Name, path)
+	}
+
+	// Separate the new table from existing content with a blank line
+	var prefix string
+	switch {
+	case len(existing) == 0:
+	case strings.HasSuffix(string(existing), "\n"):
+		prefix = "\n"
+	default:
+		prefix = "\n\n"
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, configFilePerm)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	if _, err = f.WriteString(prefix + TOMLTable(s)); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// WriteFileTask writes the spec as an executable file task under root and returns its path.
+// It refuses to overwrite an existing file.
+func WriteFileTask(root string, s TaskSpec) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+
+	path := FileTaskPath(root, s.Name)
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return "", fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+
+	//nolint:gosec // file tasks must be executable for mise to run them
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fileTaskPerm)
+	if err != nil {
+		return "", fmt.Errorf("creating %s: %w", path, err)
+	}
+	if _, err = f.WriteString(FileTask(s)); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, f.Close()
+}
+
+// tomlKey quotes a key unless it is a valid bare key.
+func tomlKey(key string) string {
+	if bareKeyPattern.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+// tomlString renders s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlStringArray renders values as an inline TOML array of strings.
+func tomlStringArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = tomlString(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// multilineQuotes open and close TOML multi-line strings.
+var multilineQuotes = []string{`"""`, `'''`}
+
+// definesTask reports whether the TOML config defines the task name, as a
+// [tasks.<name>] table, a key of a [tasks] table, a dotted tasks.<name> key
+// or any of those nested deeper. Lines inside multi-line strings, like run
+// scripts, are skipped.
+func definesTask(config, name string) bool {
+	var table []string
+	inString := ""
+	for line := range strings.Lines(config) {
+		line = strings.TrimSpace(line)
+		if inString != "" {
+			if strings.Count(line, inString)%2 == 1 {
+				inString = ""
+			}
+			continue
+		}
+
+		var path []string
+		if strings.HasPrefix(line, "[") {
+			key, rest, ok := parseTOMLKey(strings.TrimLeft(line, "["))
+			if !ok || !strings.HasPrefix(rest, "]") {
+				continue
+			}
+			table, path = key, key
+		} else {
+			key, rest, ok := parseTOMLKey(line)
+			if !ok || !strings.HasPrefix(rest, "=") {
+				continue
+			}
+			path = append(slices.Clone(table), key...)
+			for _, quote := range multilineQuotes {
+				if strings.Count(rest, quote)%2 == 1 {
+					inString = quote
+				}
+			}
+		}
+		if len(path) >= 2 && path[0] == "tasks" && path[1] == name { //nolint:mnd // tasks and the task name
+			return true
+		}
+	}
+	return false
+}
+
+// parseTOMLKey parses the dotted key line starts with, bare or quoted, and
+// returns its parts and the rest of the line.
+func parseTOMLKey(line string) ([]string, string, bool) {
+	var parts []string
+	rest := line
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		var part string
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			end := 1
+			for end < len(rest) && rest[end] != '"' {
+				if rest[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(rest) {
+				return nil, "", false
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				unquoted = rest[1:end]
+			}
+			part, rest = unquoted, rest[end+1:]
+		case strings.HasPrefix(rest, "'"):
+			end := strings.IndexByte(rest[1:], '\'')
+			if end < 0 {
+				return nil, "", false
+			}
+			part, rest = rest[1:end+1], rest[end+2:]
+		default:
+			end := strings.IndexFunc(rest, func(r rune) bool { return !bareKeyPattern.MatchString(string(r)) })
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, "", false
+			}
+			part, rest = rest[:end], rest[end:]
+		}
+		parts = append(parts, part)
+		rest = strings.TrimLeft(rest, " \t")
+		if !strings.HasPrefix(rest, ".") {
+			return parts, rest, true
+		}
+		rest = rest[1:]
+	}
+}
@@ -0,0 +1,223 @@
+package scaffold_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rshep3087/prep/internal/scaffold"
+)
+
+func TestTaskSpecValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    scaffold.TaskSpec
+		wantErr error
+	}{
+		{
+			name: "simple name",
+			spec: scaffold.TaskSpec{Name: "build", Run: "go build"},
+		},
+		{
+			name: "namespaced name",
+			spec: scaffold.TaskSpec{Name: "db:migrate", Run: "migrate up"},
+		},
+		{
+			name:    "empty name",
+			spec:    scaffold.TaskSpec{Run: "go build"},
+			wantErr: scaffold.ErrInvalidName,
+		},
+		{
+			name:    "name with spaces",
+			spec:    scaffold.TaskSpec{Name: "my task", Run: "go build"},
+			wantErr: scaffold.ErrInvalidName,
+		},
+		{
+			name:    "trailing colon",
+			spec:    scaffold.TaskSpec{Name: "db:", Run: "go build"},
+			wantErr: scaffold.ErrInvalidName,
+		},
+		{
+			name:    "missing run",
+			spec:    scaffold.TaskSpec{Name: "build", Run: "  "},
+			wantErr: scaffold.ErrRunRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spec.Validate()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDepends(t *testing.T) {
+	got := scaffold.ParseDepends("lint, test  build,")
+	want := []string{"lint", "test", "build"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseDepends() = %v, want %v", got, want)
+	}
+}
+
+func TestTOMLTable(t *testing.T) {
+	tests := []struct {
+		name string
+		spec scaffold.TaskSpec
+		want string
+	}{
+		{
+			name: "minimal task",
+			spec: scaffold.TaskSpec{Name: "build", Run: "go build"},
+			want: "[tasks.build]\nrun = \"go build\"\n",
+		},
+		{
+			name: "namespaced name is quoted",
+			spec: scaffold.TaskSpec{Name: "release:version", Run: "svu next"},
+			want: "[tasks.\"release:version\"]\nrun = \"svu next\"\n",
+		},
+		{
+			name: "all fields with escaping",
+			spec: scaffold.TaskSpec{
+				Name:        "greet",
+				Description: `Say "hi"`,
+				Run:         "echo hi\necho C:\\temp\n",
+				Depends:     []string{"build", "lint"},
+			},
+			want: "[tasks.greet]\n" +
+				"description = \"Say \\\"hi\\\"\"\n" +
+				"depends = [\"build\", \"lint\"]\n" +
+				"run = \"echo hi\\necho C:\\\\temp\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaffold.TOMLTable(tt.spec); got != tt.want {
+				t.Errorf("TOMLTable() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileTask(t *testing.T) {
+	spec := scaffold.TaskSpec{
+		Name:        "deploy",
+		Description: "Deploy the app",
+		Run:         "echo deploying",
+		Depends:     []string{"build"},
+	}
+	want := "#!/usr/bin/env bash\n" +
+		"#MISE description=\"Deploy the app\"\n" +
+		"#MISE depends=[\"build\"]\n" +
+		"\nset -euo pipefail\n\n" +
+		"echo deploying\n"
+
+	if got := scaffold.FileTask(spec); got != want {
+		t.Errorf("FileTask() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFileTaskPath(t *testing.T) {
+	got := scaffold.FileTaskPath("/project", "db:migrate")
+	want := filepath.Join("/project", "mise-tasks", "db", "migrate")
+	if got != want {
+		t.Errorf("FileTaskPath() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendToConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mise.toml")
+	if err := os.WriteFile(path, []byte("[tools]\ngo = \"1.25\""), 0o644); err != nil {
+		t.Fatalf("failed to create config: %v", err)
+	}
+
+	spec := scaffold.TaskSpec{Name: "build", Run: "go build"}
+	if err := scaffold.AppendToConfig(path, spec); err != nil {
+		t.Fatalf("AppendToConfig failed: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	want := "[tools]\ngo = \"1.25\"\n\n[tasks.build]\nrun = \"go build\"\n"
+	if string(got) != want {
+		t.Errorf("config =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAppendToConfig_InvalidSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mise.toml")
+	err := scaffold.AppendToConfig(path, scaffold.TaskSpec{Name: "build"})
+	if !errors.Is(err, scaffold.ErrRunRequired) {
+		t.Errorf("AppendToConfig() = %v, want %v", err, scaffold.ErrRunRequired)
+	}
+	if _, statErr := os.Stat(path); !errors.Is(statErr, os.ErrNotExist) {
+		t.Error("config file should not be created for an invalid spec")
+	}
+}
+
+func TestAppendToConfig_ExistingTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		exists bool
+	}{
+		{name: "table", config: "[tasks.build]\nrun = \"make\"\n", exists: true},
+		{name: "quoted table", config: "[tasks . \"build\"]\nrun = \"make\"\n", exists: true},
+		{name: "nested table", config: "[tasks.build.env]\nCGO_ENABLED = \"0\"\n", exists: true},
+		{name: "key of tasks table", config: "[tasks]\nbuild = \"make\"\n", exists: true},
+		{name: "dotted key", config: "tasks.build.run = 'make'\n", exists: true},
+		{name: "other task", config: "[tasks.build-all]\nrun = \"make\"\n"},
+		{name: "other table", config: "[env]\nbuild = \"1\"\n"},
+		{
+			name:   "script mentioning the table",
+			config: "[tasks.docs]\nrun = \"\"\"\ncat <<EOF\n[tasks.build]\nEOF\n\"\"\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mise.toml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatalf("failed to create config: %v", err)
+			}
+
+			err := scaffold.AppendToConfig(path, scaffold.TaskSpec{Name: "build", Run: "go build"})
+			if got := errors.Is(err, scaffold.ErrTaskExists); got != tt.exists {
+				t.Fatalf("AppendToConfig() = %v, want ErrTaskExists %v", err, tt.exists)
+			}
+			if content, _ := os.ReadFile(path); tt.exists && string(content) != tt.config {
+				t.Errorf("config changed to\n%s", content)
+			}
+		})
+	}
+}
+
+func TestWriteFileTask(t *testing.T) {
+	root := t.TempDir()
+	spec := scaffold.TaskSpec{Name: "db:seed", Run: "echo seeding"}
+
+	path, err := scaffold.WriteFileTask(root, spec)
+	if err != nil {
+		t.Fatalf("WriteFileTask failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat task file: %v", err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("task file mode = %v, want executable", info.Mode())
+	}
+
+	// Writing the same task again must not clobber it
+	if _, err = scaffold.WriteFileTask(root, spec); err == nil {
+		t.Error("expected error when task file already exists, got nil")
+	}
+}
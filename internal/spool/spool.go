@@ -0,0 +1,111 @@
+// Package spool keeps task output that no longer fits in memory in a temporary file.
+package spool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// File is an append-only temporary file of output lines.
+// It is safe for concurrent use, so a snapshot can be read while lines are appended.
+type File struct {
+	f *os.File
+
+	mu        sync.Mutex
+	size      int64 // bytes written
+	lines     int   // lines written
+	snapshots int   // snapshots not closed yet, which keep the file around
+	closed    bool  // whether Close was called
+}
+
+// snapshot reads the lines of a File written before it was taken.
+type snapshot struct {
+	*io.SectionReader
+	s    *File
+	once sync.Once
+}
+
+// Close releases the snapshot, removing the file if it was the last one of a closed File.
+func (r *snapshot) Close() error {
+	r.once.Do(func() {
+		r.s.mu.Lock()
+		defer r.s.mu.Unlock()
+		r.s.snapshots--
+		r.s.remove()
+	})
+	return nil
+}
+
+// New creates a spool file in the default temporary directory.
+func New() (*File, error) {
+	f, err := os.CreateTemp("", "prep-output-*.log")
+	if err != nil {
+		return nil, fmt.Errorf("create spool file: %w", err)
+	}
+	return &File{f: f}, nil
+}
+
+// Write appends lines, each terminated by a newline.
+func (s *File) Write(lines ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, line := range lines {
+		n, err := io.WriteString(s.f, line+"\n")
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("write spool file: %w", err)
+		}
+		s.lines++
+	}
+	return nil
+}
+
+// Lines returns the number of lines written. A nil File has none.
+func (s *File) Lines() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lines
+}
+
+// Snapshot returns a reader over the lines written so far. Lines appended
+// afterwards are not part of the snapshot. The file is kept until the
+// snapshot is closed, even if the File is closed first. A nil File yields an
+// empty reader.
+func (s *File) Snapshot() io.ReadCloser {
+	if s == nil {
+		return io.NopCloser(strings.NewReader(""))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots++
+	return &snapshot{SectionReader: io.NewSectionReader(s.f, 0, s.size), s: s}
+}
+
+// Close closes and removes the spool file once its snapshots are closed.
+// It is safe to call on a nil File.
+func Close(s *File) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.remove()
+}
+
+// remove closes and removes the file if the File is closed and no snapshot
+// reads it. s.mu must be held.
+func (s *File) remove() {
+	if !s.closed || s.snapshots > 0 {
+		return
+	}
+	_ = s.f.Close()
+	_ = os.Remove(s.f.Name())
+}
@@ -0,0 +1,92 @@
+package spool
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestFile_WriteAndSnapshot(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer Close(s)
+
+	if err := s.Write("first", "second"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	snapshot := s.Snapshot()
+
+	// Lines written after the snapshot are not part of it
+	if err := s.Write("third"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	got, err := io.ReadAll(snapshot)
+	if err != nil {
+		t.Fatalf("reading snapshot: %v", err)
+	}
+	if string(got) != "first\nsecond\n" {
+		t.Errorf("snapshot = %q, want %q", got, "first\nsecond\n")
+	}
+	if s.Lines() != 3 {
+		t.Errorf("Lines() = %d, want 3", s.Lines())
+	}
+}
+
+func TestClose_RemovesFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	name := s.f.Name()
+	Close(s)
+
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spool file still exists after Close: %v", err)
+	}
+}
+
+func TestClose_KeepsFileForSnapshots(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	s, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := s.Write("first"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	name := s.f.Name()
+	snapshot := s.Snapshot()
+	Close(s)
+
+	// A save still reading the snapshot gets every line
+	got, err := io.ReadAll(snapshot)
+	if err != nil || string(got) != "first\n" {
+		t.Errorf("snapshot after Close = %q, %v, want %q", got, err, "first\n")
+	}
+	if err := snapshot.Close(); err != nil {
+		t.Fatalf("closing snapshot: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spool file still exists after its last snapshot closed: %v", err)
+	}
+}
+
+func TestNilFile(t *testing.T) {
+	var s *File
+	if s.Lines() != 0 {
+		t.Errorf("Lines() = %d, want 0", s.Lines())
+	}
+	got, err := io.ReadAll(s.Snapshot())
+	if err != nil || len(got) != 0 {
+		t.Errorf("Snapshot() = %q, %v, want empty", got, err)
+	}
+	Close(s)
+}
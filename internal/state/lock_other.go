@@ -0,0 +1,9 @@
+//go:build !unix
+
+package state
+
+// lockFile is a no-op, file locks are only taken on unix. Changes are still
+// applied to the file's current content, but may race with other processes.
+func lockFile(_ string) (func(), error) {
+	return func() {}, nil
+}
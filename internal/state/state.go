@@ -0,0 +1,213 @@
+// Package state persists per-project favorites and task run history.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxRunsPerProject bounds the run history kept for each project.
+const maxRunsPerProject = 500
+
+// File permissions for the state file.
+const (
+	dirPerm  = 0o755
+	filePerm = 0o600
+)
+
+// Run records a single execution of a task.
+// Runs recorded before stats were kept have a zero Duration.
+type Run struct {
+	Task      string        `json:"task"`
+	Args      []string      `json:"args,omitempty"`
+	Env       []string      `json:"env,omitempty"` // KEY=VALUE overrides the task ran with
+	StartedAt time.Time     `json:"started_at"`
+	ExitCode  int           `json:"exit_code"`
+	Signal    string        `json:"signal,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	CPUTime   time.Duration `json:"cpu_time,omitempty"`
+	PeakRSS   int64         `json:"peak_rss,omitempty"` // bytes, only reported on Linux
+}
+
+// project holds the persisted state for one project directory.
+type project struct {
+	Favorites []string `json:"favorites,omitempty"`
+	Runs      []Run    `json:"runs,omitempty"`
+}
+
+// Store persists favorites and run history keyed by project directory.
+// It is safe for concurrent use, also by several prep processes sharing the
+// state file: each change is applied to what is on disk while holding a lock.
+type Store struct {
+	path string // empty keeps state in memory only
+
+	mu       sync.Mutex
+	projects map[string]*project
+}
+
+// DefaultPath returns the default location of the state file,
+// $XDG_STATE_HOME/prep/state.json or ~/.local/state/prep/state.json.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "prep", "state.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "prep", "state.json"), nil
+}
+
+// Open loads the state file at path. A missing file yields an empty store.
+// An empty path returns a store that keeps state in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, projects: make(map[string]*project)}
+	if path == "" {
+		return s, nil
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replaces the projects of s with those in the state file. A missing
+// file leaves them empty. Callers must hold s.mu, or own s.
+func (s *Store) load() error {
+	projects := make(map[string]*project)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.projects = projects
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	if err = json.Unmarshal(data, &projects); err != nil {
+		return fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	if projects == nil {
+		projects = make(map[string]*project)
+	}
+	s.projects = projects
+	return nil
+}
+
+// Favorites returns the favorite task names for the project.
+func (s *Store) Favorites(dir string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.projects[dir]; ok {
+		return slices.Clone(p.Favorites)
+	}
+	return nil
+}
+
+// ToggleFavorite adds or removes task from the project's favorites and saves the store.
+// It reports whether the task is now a favorite.
+func (s *Store) ToggleFavorite(dir, task string) (bool, error) {
+	var favorite bool
+	err := s.update(dir, func(p *project) {
+		favorite = !slices.Contains(p.Favorites, task)
+		if favorite {
+			p.Favorites = append(p.Favorites, task)
+		} else {
+			p.Favorites = slices.DeleteFunc(p.Favorites, func(name string) bool { return name == task })
+		}
+	})
+	return favorite, err
+}
+
+// RecordRun appends a run to the project's history and saves the store.
+// The oldest runs are dropped once the history exceeds its limit.
+func (s *Store) RecordRun(dir string, run Run) error {
+	return s.update(dir, func(p *project) {
+		p.Runs = append(p.Runs, run)
+		if len(p.Runs) > maxRunsPerProject {
+			p.Runs = slices.Clone(p.Runs[len(p.Runs)-maxRunsPerProject:])
+		}
+	})
+}
+
+// Runs returns the project's run history, oldest first.
+func (s *Store) Runs(dir string) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.projects[dir]; ok {
+		return slices.Clone(p.Runs)
+	}
+	return nil
+}
+
+// project returns the state for dir, creating it if needed. Callers must hold s.mu.
+func (s *Store) project(dir string) *project {
+	p, ok := s.projects[dir]
+	if !ok {
+		p = &project{}
+		s.projects[dir] = p
+	}
+	return p
+}
+
+// update applies change to the state of dir and saves the store. With a
+// state file, other processes may have saved since it was read, so the
+// change is applied to the file's current content while holding its lock.
+func (s *Store) update(dir string, change func(p *project)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		change(s.project(dir))
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), dirPerm); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(s.path), err)
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", s.path, err)
+	}
+	defer unlock()
+
+	if err = s.load(); err != nil {
+		return err
+	}
+	change(s.project(dir))
+	return s.save()
+}
+
+// save writes the store to disk atomically. Callers must hold s.mu and the file lock.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.projects, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding state: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a truncated file
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file for %s: %w", s.path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing %s: %w", tmp.Name(), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", tmp.Name(), err)
+	}
+	if err = os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("renaming %s: %w", tmp.Name(), err)
+	}
+	return nil
+}
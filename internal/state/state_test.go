@@ -0,0 +1,171 @@
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rshep3087/prep/internal/state"
+)
+
+func TestOpen_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got := s.Favorites("/project"); len(got) != 0 {
+		t.Errorf("Favorites() = %v, want empty", got)
+	}
+}
+
+func TestOpen_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("failed to write state file: %v", err)
+	}
+
+	if _, err := state.Open(path); err == nil {
+		t.Error("expected error for invalid state file, got nil")
+	}
+}
+
+func TestToggleFavorite_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	s, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	favorite, err := s.ToggleFavorite("/project", "build")
+	if err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+	if !favorite {
+		t.Error("expected build to become a favorite")
+	}
+	if _, err = s.ToggleFavorite("/project", "test"); err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+
+	// Favorites are scoped per project
+	if got := s.Favorites("/other"); len(got) != 0 {
+		t.Errorf("Favorites(/other) = %v, want empty", got)
+	}
+
+	reopened, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if got, want := reopened.Favorites("/project"), []string{"build", "test"}; !slices.Equal(got, want) {
+		t.Errorf("Favorites() = %v, want %v", got, want)
+	}
+
+	// Toggling again removes it
+	favorite, err = reopened.ToggleFavorite("/project", "build")
+	if err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+	if favorite {
+		t.Error("expected build to no longer be a favorite")
+	}
+	if got, want := reopened.Favorites("/project"), []string{"test"}; !slices.Equal(got, want) {
+		t.Errorf("Favorites() = %v, want %v", got, want)
+	}
+}
+
+func TestRecordRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 510 {
+		run := state.Run{Task: "build", StartedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err = s.RecordRun("/project", run); err != nil {
+			t.Fatalf("RecordRun failed: %v", err)
+		}
+	}
+
+	runs := s.Runs("/project")
+	if len(runs) != 500 {
+		t.Fatalf("got %d runs, want history capped at 500", len(runs))
+	}
+	// The oldest runs are dropped
+	if want := start.Add(10 * time.Minute); !runs[0].StartedAt.Equal(want) {
+		t.Errorf("oldest run started at %v, want %v", runs[0].StartedAt, want)
+	}
+}
+
+func TestOpen_EmptyPathKeepsStateInMemory(t *testing.T) {
+	s, err := state.Open("")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err = s.ToggleFavorite("/project", "build"); err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+	if got := s.Favorites("/project"); !slices.Equal(got, []string{"build"}) {
+		t.Errorf("Favorites() = %v, want [build]", got)
+	}
+}
+
+func TestStore_SharedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	first, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	second, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	// Two processes in different projects keep each other's changes
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = first.RecordRun("/api", state.Run{Task: "build", ExitCode: i})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = second.RecordRun("/web", state.Run{Task: "test", ExitCode: i})
+		}()
+	}
+	wg.Wait()
+	if _, err = first.ToggleFavorite("/api", "build"); err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+	if _, err = second.ToggleFavorite("/web", "test"); err != nil {
+		t.Fatalf("ToggleFavorite failed: %v", err)
+	}
+
+	reopened, err := state.Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	if api, web := reopened.Runs("/api"), reopened.Runs("/web"); len(api) != 20 || len(web) != 20 {
+		t.Errorf("got %d and %d runs, want every run of both stores", len(api), len(web))
+	}
+	if !slices.Equal(reopened.Favorites("/api"), []string{"build"}) ||
+		!slices.Equal(reopened.Favorites("/web"), []string{"test"}) {
+		t.Errorf("favorites = %v and %v, want both stores' favorites",
+			reopened.Favorites("/api"), reopened.Favorites("/web"))
+	}
+
+	// No temp files are left behind
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+	if len(files) != 0 {
+		t.Errorf("temp files left: %v", files)
+	}
+}
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// globAny is the pattern segment that matches any number of directories.
+const globAny = "**"
+
+// globMeta are the characters that make a pattern segment match more than its text.
+const globMeta = `*?[\`
+
+// ignoredDirs are directories "**" doesn't descend into: repositories and
+// dependencies would add a watch for every directory below them. Patterns
+// that name them, like vendor/**/*.go, still reach them.
+var ignoredDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true, "target": true}
+
+// MatchGlob reports whether name matches pattern. Both use forward slashes.
+// Segments are matched with path.Match, and a "**" segment matches zero or
+// more directories, like the sources globs of mise tasks.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == globAny {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// globMayContain reports whether files matching pattern can be inside dir,
+// so the directory needs to be watched. dir is relative to the pattern's root
+// and "." is the root itself. "**" doesn't reach ignoredDirs.
+func globMayContain(pattern, dir string) bool {
+	if dir == "." {
+		return true
+	}
+	segments := strings.Split(pattern, "/")
+	dirs := strings.Split(dir, "/")
+	for i, d := range dirs {
+		if i < len(segments) && segments[i] == globAny {
+			return !slices.ContainsFunc(dirs[i:], func(d string) bool { return ignoredDirs[d] })
+		}
+		// The last segment matches files, not directories
+		if i >= len(segments)-1 {
+			return false
+		}
+		if ok, err := path.Match(segments[i], d); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// splitGlobRoot splits an absolute pattern into the directory before its
+// first segment with a wildcard and the pattern relative to it, both with
+// forward slashes.
+func splitGlobRoot(pattern string) (string, string) {
+	segments := strings.Split(pattern, "/")
+	fixed := 0
+	for fixed < len(segments)-1 && !strings.ContainsAny(segments[fixed], globMeta) {
+		fixed++
+	}
+	root := strings.Join(segments[:fixed], "/")
+	if root == "" {
+		root = "/"
+	}
+	return root, strings.Join(segments[fixed:], "/")
+}
@@ -0,0 +1,72 @@
+package watcher
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.go", name: "main.go", want: true},
+		{pattern: "*.go", name: "cmd/main.go", want: false},
+		{pattern: "src/**/*.go", name: "src/main.go", want: true},
+		{pattern: "src/**/*.go", name: "src/a/b/main.go", want: true},
+		{pattern: "src/**/*.go", name: "test/main.go", want: false},
+		{pattern: "**/*.ts", name: "web/app.ts", want: true},
+		{pattern: "src/**", name: "src/a/b", want: true},
+		{pattern: "go.{mod,sum}", name: "go.mod", want: false},
+		{pattern: "[", name: "[", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGlobMayContain(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		{pattern: "src/*.go", dir: ".", want: true},
+		{pattern: "src/*.go", dir: "src", want: true},
+		{pattern: "src/*.go", dir: "src/sub", want: false},
+		{pattern: "src/*.go", dir: "docs", want: false},
+		{pattern: "src/**/*.go", dir: "src/a/b", want: true},
+		{pattern: "src/**", dir: "src/a", want: true},
+		{pattern: "*.go", dir: "cmd", want: false},
+		{pattern: "**/*.ts", dir: "node_modules", want: false},
+		{pattern: "**/*.go", dir: "src/vendor/lib", want: false},
+		{pattern: "src/**/*.rs", dir: "src/target", want: false},
+		{pattern: "vendor/**/*.go", dir: "vendor/lib", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := globMayContain(tt.pattern, tt.dir); got != tt.want {
+			t.Errorf("globMayContain(%q, %q) = %v, want %v", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestSplitGlobRoot(t *testing.T) {
+	tests := []struct {
+		pattern string
+		root    string
+		rest    string
+	}{
+		{pattern: "/etc/app/*.conf", root: "/etc/app", rest: "*.conf"},
+		{pattern: "/srv/shared/**/*.sql", root: "/srv/shared", rest: "**/*.sql"},
+		{pattern: "/etc/app.conf", root: "/etc", rest: "app.conf"},
+		{pattern: "/*.conf", root: "/", rest: "*.conf"},
+	}
+
+	for _, tt := range tests {
+		if root, rest := splitGlobRoot(tt.pattern); root != tt.root || rest != tt.rest {
+			t.Errorf("splitGlobRoot(%q) = %q, %q, want %q, %q", tt.pattern, root, rest, tt.root, tt.rest)
+		}
+	}
+}
@@ -1,79 +1,291 @@
-// Package watcher provides file watching functionality for config files.
+// Package watcher provides file watching functionality for config files and
+// the sources of tasks.
 package watcher
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/fsnotify/fsnotify"
 )
 
+// DefaultDebounce is how long the watcher waits for events to settle before
+// emitting a FileChangedMsg.
+const DefaultDebounce = 300 * time.Millisecond
+
 // MessageSender abstracts the ability to send messages.
 type MessageSender interface {
 	Send(msg tea.Msg)
 }
 
-// FileChangedMsg is sent when a watched config file changes.
+// FileChangedMsg is sent once a burst of changes to watched files has settled.
+// Paths holds every file that changed during the burst, sorted and deduplicated.
 type FileChangedMsg struct {
-	Path string
+	Paths []string
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher)
+
+// WithDebounce sets how long the watcher waits after the last event before
+// emitting a FileChangedMsg.
+func WithDebounce(d time.Duration) Option {
+	return func(w *Watcher) {
+		w.debounce = d
+	}
+}
+
+// WithMessage sets the message sent for a burst of changes, replacing the
+// FileChangedMsg. It lets several watchers report to the same program.
+func WithMessage(newMsg func(paths []string) tea.Msg) Option {
+	return func(w *Watcher) {
+		w.newMsg = newMsg
+	}
 }
 
-// StartFileWatcher creates an fsnotify watcher and monitors config files.
+// Watcher watches a set of files and emits debounced FileChangedMsg messages.
+type Watcher struct {
+	fsw      *fsnotify.Watcher
+	sender   MessageSender
+	debounce time.Duration
+	newMsg   func(paths []string) tea.Msg
+
+	mu    sync.Mutex
+	files map[string]bool // files we report changes for
+	globs []rootedGlob    // patterns of files we report changes for
+	dirs  map[string]bool // parent directories registered with fsnotify
+}
+
+// rootedGlob is a glob pattern relative to a root directory.
+type rootedGlob struct {
+	root    string
+	pattern string
+	source  string // the pattern as it was added
+}
+
+// relativeTo returns path relative to the glob's root with forward slashes,
+// and false if path is outside the root.
+func (g rootedGlob) relativeTo(path string) (string, bool) {
+	rel, err := filepath.Rel(g.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// StartFileWatcher creates a Watcher and monitors the given files.
 // It watches parent directories (more reliable for editor saves) and filters
-// events to only the specified config files.
-func StartFileWatcher(paths []string, sender MessageSender) (*fsnotify.Watcher, error) {
-	watcher, err := fsnotify.NewWatcher()
+// events to only the specified files. Events are debounced on the trailing
+// edge so the final write of a burst is always reported.
+func StartFileWatcher(paths []string, sender MessageSender, opts ...Option) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
 
-	// Build a set of config file paths for filtering
-	configFiles := make(map[string]bool)
-	for _, p := range paths {
-		configFiles[p] = true
+	w := &Watcher{
+		fsw:      fsw,
+		sender:   sender,
+		debounce: DefaultDebounce,
+		newMsg:   func(paths []string) tea.Msg { return FileChangedMsg{Paths: paths} },
+		files:    make(map[string]bool),
+		dirs:     make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
 
-	// Add parent directories to watch
-	if addErr := addWatchDirs(watcher, paths); addErr != nil {
-		_ = watcher.Close()
+	if addErr := w.Add(paths...); addErr != nil {
+		_ = fsw.Close()
 		return nil, addErr
 	}
 
 	// Start goroutine to listen for events
-	go watchLoop(watcher, configFiles, sender)
+	go w.watchLoop()
 
-	return watcher, nil
+	return w, nil
 }
 
-// addWatchDirs adds parent directories of the given paths to the watcher.
-func addWatchDirs(watcher *fsnotify.Watcher, paths []string) error {
-	watchedDirs := make(map[string]bool)
+// Add starts watching additional files. Files that are already watched are ignored.
+func (w *Watcher) Add(paths ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
 	for _, p := range paths {
-		dir := filepath.Dir(p)
-		if watchedDirs[dir] {
-			continue
+		if err := w.watchDirLocked(filepath.Dir(p)); err != nil {
+			return err
+		}
+		w.files[p] = true
+	}
+	return nil
+}
+
+// AddGlobs starts watching the files that match any of patterns. Patterns
+// use forward slashes and may use "**" to match any number of directories,
+// but not directories like .git or node_modules it doesn't name. Relative
+// patterns are relative to root. Directories created later are watched when
+// they may contain matching files.
+func (w *Watcher) AddGlobs(root string, patterns ...string) error {
+	var roots []string
+	w.mu.Lock()
+	for _, p := range patterns {
+		g := rootedGlob{root: root, pattern: strings.TrimPrefix(filepath.ToSlash(p), "./"), source: p}
+		if filepath.IsAbs(p) {
+			var globRoot string
+			globRoot, g.pattern = splitGlobRoot(filepath.ToSlash(p))
+			g.root = filepath.FromSlash(globRoot)
+		}
+		if !slices.Contains(roots, g.root) {
+			roots = append(roots, g.root)
+		}
+		w.globs = append(w.globs, g)
+	}
+	w.mu.Unlock()
+
+	for _, dir := range roots {
+		if err := w.addGlobDirs(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addGlobDirs watches dir and the directories below it that may contain
+// files matching a glob. Directories that can't be read are skipped.
+func (w *Watcher) addGlobDirs(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return fmt.Errorf("watching %s: %w", dir, err)
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
 		}
-		if err := watcher.Add(dir); err != nil {
-			return fmt.Errorf("watching %s: %w", dir, err)
+		patterns := w.globsReaching(path)
+		if len(patterns) == 0 {
+			return filepath.SkipDir
 		}
-		watchedDirs[dir] = true
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if err := w.watchDirLocked(path); err != nil {
+			// Running out of watches names the directory, the patterns say which source to narrow
+			return fmt.Errorf("%w (for sources %s)", err, strings.Join(patterns, ", "))
+		}
+		return nil
+	})
+}
+
+// watchDirLocked registers dir with fsnotify unless it already is. w.mu must be held.
+func (w *Watcher) watchDirLocked(dir string) error {
+	if w.dirs[dir] {
+		return nil
+	}
+	if err := w.fsw.Add(dir); err != nil {
+		return fmt.Errorf("watching %s: %w", dir, err)
 	}
+	w.dirs[dir] = true
 	return nil
 }
 
-// watchLoop listens for fsnotify events and sends messages for matching config files.
-func watchLoop(watcher *fsnotify.Watcher, configFiles map[string]bool, sender MessageSender) {
+// globsReaching returns the patterns, as added, of the globs that may match files in dir.
+func (w *Watcher) globsReaching(dir string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var patterns []string
+	for _, g := range w.globs {
+		if rel, ok := g.relativeTo(dir); ok && globMayContain(g.pattern, rel) {
+			patterns = append(patterns, g.source)
+		}
+	}
+	return patterns
+}
+
+// isWatched reports whether changes to path should be reported.
+func (w *Watcher) isWatched(path string) bool {
+	w.mu.Lock()
+	watched := w.files[path]
+	w.mu.Unlock()
+	return watched || w.matchesGlob(path)
+}
+
+// matchesGlob reports whether path matches a glob.
+func (w *Watcher) matchesGlob(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, g := range w.globs {
+		if rel, ok := g.relativeTo(path); ok && MatchGlob(g.pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchNewDir starts watching a directory created below a glob's root.
+func (w *Watcher) watchNewDir(path string) {
+	w.mu.Lock()
+	hasGlobs := len(w.globs) > 0
+	w.mu.Unlock()
+	if !hasGlobs {
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		_ = w.addGlobDirs(path)
+	}
+}
+
+// watchLoop listens for fsnotify events, coalesces the changed paths and
+// sends a single FileChangedMsg once no new events arrive for the debounce interval.
+func (w *Watcher) watchLoop() {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	// fire is nil while nothing is pending so the timer case never selects.
+	var fire <-chan time.Time
+
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case event, ok := <-w.fsw.Events:
 			if !ok {
 				return
 			}
-			if event.Has(fsnotify.Write) && configFiles[event.Name] {
-				sender.Send(FileChangedMsg{Path: event.Name})
+			// Editors that save atomically create a new file instead of writing in
+			// place, so removals only count for globs, where they change which
+			// files match
+			changed := event.Has(fsnotify.Write) || event.Has(fsnotify.Create)
+			removed := event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+			if !changed && (!removed || !w.matchesGlob(event.Name)) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				w.watchNewDir(event.Name)
+			}
+			if !w.isWatched(event.Name) {
+				continue
+			}
+			pending[event.Name] = true
+			timer.Reset(w.debounce)
+			fire = timer.C
+		case <-fire:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
 			}
-		case _, ok := <-watcher.Errors:
+			slices.Sort(paths)
+			clear(pending)
+			fire = nil
+			w.sender.Send(w.newMsg(paths))
+		case _, ok := <-w.fsw.Errors:
 			if !ok {
 				return
 			}
@@ -82,8 +294,8 @@ func watchLoop(watcher *fsnotify.Watcher, configFiles map[string]bool, sender Me
 }
 
 // Close safely closes a file watcher if it exists.
-func Close(w *fsnotify.Watcher) {
+func Close(w *Watcher) {
 	if w != nil {
-		_ = w.Close()
+		_ = w.fsw.Close()
 	}
 }
@@ -3,6 +3,7 @@ package watcher_test
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +31,9 @@ func (m *mockSender) Messages() []tea.Msg {
 	return append([]tea.Msg{}, m.messages...)
 }
 
+// testDebounce keeps the tests fast while still exercising the debounce path.
+const testDebounce = 20 * time.Millisecond
+
 func TestStartFileWatcher(t *testing.T) {
 	// Create a temp directory with a config file
 	tmpDir := t.TempDir()
@@ -41,7 +45,7 @@ func TestStartFileWatcher(t *testing.T) {
 	}
 
 	sender := &mockSender{}
-	w, err := watcher.StartFileWatcher([]string{configPath}, sender)
+	w, err := watcher.StartFileWatcher([]string{configPath}, sender, watcher.WithDebounce(testDebounce))
 	if err != nil {
 		t.Fatalf("StartFileWatcher failed: %v", err)
 	}
@@ -68,7 +72,7 @@ func TestStartFileWatcher(t *testing.T) {
 	found := false
 	for _, msg := range messages {
 		if changed, ok := msg.(watcher.FileChangedMsg); ok {
-			if changed.Path == configPath {
+			if slices.Contains(changed.Paths, configPath) {
 				found = true
 				break
 			}
@@ -93,7 +97,7 @@ func TestStartFileWatcher_IgnoresUnwatchedFiles(t *testing.T) {
 	}
 
 	sender := &mockSender{}
-	w, err := watcher.StartFileWatcher([]string{watchedPath}, sender)
+	w, err := watcher.StartFileWatcher([]string{watchedPath}, sender, watcher.WithDebounce(testDebounce))
 	if err != nil {
 		t.Fatalf("StartFileWatcher failed: %v", err)
 	}
@@ -112,7 +116,7 @@ func TestStartFileWatcher_IgnoresUnwatchedFiles(t *testing.T) {
 	messages := sender.Messages()
 	for _, msg := range messages {
 		if changed, ok := msg.(watcher.FileChangedMsg); ok {
-			if changed.Path == unwatchedPath {
+			if slices.Contains(changed.Paths, unwatchedPath) {
 				t.Errorf("should not receive FileChangedMsg for unwatched file %s", unwatchedPath)
 			}
 		}
@@ -134,7 +138,7 @@ func TestStartFileWatcher_MultipleFilesInSameDir(t *testing.T) {
 
 	sender := &mockSender{}
 	// Watch both files - they share the same parent directory
-	w, err := watcher.StartFileWatcher([]string{config1, config2}, sender)
+	w, err := watcher.StartFileWatcher([]string{config1, config2}, sender, watcher.WithDebounce(testDebounce))
 	if err != nil {
 		t.Fatalf("StartFileWatcher failed: %v", err)
 	}
@@ -160,10 +164,10 @@ func TestStartFileWatcher_MultipleFilesInSameDir(t *testing.T) {
 	gotConfig2 := false
 	for _, msg := range messages {
 		if changed, ok := msg.(watcher.FileChangedMsg); ok {
-			if changed.Path == config1 {
+			if slices.Contains(changed.Paths, config1) {
 				gotConfig1 = true
 			}
-			if changed.Path == config2 {
+			if slices.Contains(changed.Paths, config2) {
 				gotConfig2 = true
 			}
 		}
@@ -204,3 +208,232 @@ func TestStartFileWatcher_EmptyPaths(t *testing.T) {
 
 	// Should work fine with no paths to watch
 }
+
+func TestStartFileWatcher_CoalescesBurst(t *testing.T) {
+	tmpDir := t.TempDir()
+	config1 := filepath.Join(tmpDir, "mise.toml")
+	config2 := filepath.Join(tmpDir, "mise.local.toml")
+
+	if err := os.WriteFile(config1, []byte("config1"), 0o644); err != nil {
+		t.Fatalf("failed to create config1: %v", err)
+	}
+	if err := os.WriteFile(config2, []byte("config2"), 0o644); err != nil {
+		t.Fatalf("failed to create config2: %v", err)
+	}
+
+	sender := &mockSender{}
+	w, err := watcher.StartFileWatcher([]string{config1, config2}, sender, watcher.WithDebounce(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("StartFileWatcher failed: %v", err)
+	}
+	defer watcher.Close(w)
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Write a burst of changes well within the debounce interval
+	for i := range 5 {
+		target := config1
+		if i%2 == 1 {
+			target = config2
+		}
+		if writeErr := os.WriteFile(target, []byte{byte('a' + i)}, 0o644); writeErr != nil {
+			t.Fatalf("failed to write %s: %v", target, writeErr)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	messages := sender.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected exactly one FileChangedMsg for the burst, got %d: %v", len(messages), messages)
+	}
+
+	changed, ok := messages[0].(watcher.FileChangedMsg)
+	if !ok {
+		t.Fatalf("expected watcher.FileChangedMsg, got %T", messages[0])
+	}
+	want := []string{config2, config1} // sorted: mise.local.toml < mise.toml
+	if !slices.Equal(changed.Paths, want) {
+		t.Errorf("Paths = %v, want %v", changed.Paths, want)
+	}
+}
+
+func TestWatcher_Add(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "mise.toml")
+	otherDir := t.TempDir()
+	taskPath := filepath.Join(otherDir, "build")
+
+	if err := os.WriteFile(configPath, []byte("config"), 0o644); err != nil {
+		t.Fatalf("failed to create config file: %v", err)
+	}
+	if err := os.WriteFile(taskPath, []byte("task"), 0o644); err != nil {
+		t.Fatalf("failed to create task file: %v", err)
+	}
+
+	sender := &mockSender{}
+	w, err := watcher.StartFileWatcher([]string{configPath}, sender, watcher.WithDebounce(testDebounce))
+	if err != nil {
+		t.Fatalf("StartFileWatcher failed: %v", err)
+	}
+	defer watcher.Close(w)
+
+	if addErr := w.Add(taskPath); addErr != nil {
+		t.Fatalf("Add failed: %v", addErr)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if writeErr := os.WriteFile(taskPath, []byte("modified"), 0o644); writeErr != nil {
+		t.Fatalf("failed to write task file: %v", writeErr)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	found := false
+	for _, msg := range sender.Messages() {
+		if changed, ok := msg.(watcher.FileChangedMsg); ok && slices.Contains(changed.Paths, taskPath) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected FileChangedMsg for added file %s", taskPath)
+	}
+}
+
+// sourcesChangedMsg is a custom message for TestWatcher_AddGlobs.
+type sourcesChangedMsg struct {
+	paths []string
+}
+
+func TestWatcher_AddGlobs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src/pkg", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+
+	sender := &mockSender{}
+	w, err := watcher.StartFileWatcher(nil, sender,
+		watcher.WithDebounce(testDebounce),
+		watcher.WithMessage(func(paths []string) tea.Msg { return sourcesChangedMsg{paths: paths} }),
+	)
+	if err != nil {
+		t.Fatalf("StartFileWatcher failed: %v", err)
+	}
+	defer watcher.Close(w)
+
+	if addErr := w.AddGlobs(root, "src/**/*.go", "./go.mod"); addErr != nil {
+		t.Fatalf("AddGlobs failed: %v", addErr)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// A directory created after AddGlobs is watched too
+	if mkErr := os.Mkdir(filepath.Join(root, "src", "new"), 0o755); mkErr != nil {
+		t.Fatalf("failed to create directory: %v", mkErr)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	files := map[string]bool{
+		"go.mod":             true,
+		"src/main.go":        true,
+		"src/pkg/pkg.go":     true,
+		"src/new/new.go":     true,
+		"src/pkg/README.md":  false,
+		"docs/guide.go":      false,
+		"src/pkg/pkg.go.bak": false,
+	}
+	for name := range files {
+		if writeErr := os.WriteFile(filepath.Join(root, name), []byte("x"), 0o644); writeErr != nil {
+			t.Fatalf("failed to write %s: %v", name, writeErr)
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	changed := map[string]bool{}
+	for _, msg := range sender.Messages() {
+		sources, ok := msg.(sourcesChangedMsg)
+		if !ok {
+			t.Fatalf("expected sourcesChangedMsg, got %T", msg)
+		}
+		for _, p := range sources.paths {
+			rel, _ := filepath.Rel(root, p)
+			changed[filepath.ToSlash(rel)] = true
+		}
+	}
+	for name, want := range files {
+		if changed[name] != want {
+			t.Errorf("change to %s reported = %v, want %v", name, changed[name], want)
+		}
+	}
+
+	// Deleting or renaming a matching file is a change too
+	sender.mu.Lock()
+	sender.messages = nil
+	sender.mu.Unlock()
+	if rmErr := os.Remove(filepath.Join(root, "src", "main.go")); rmErr != nil {
+		t.Fatalf("failed to remove: %v", rmErr)
+	}
+	if mvErr := os.Rename(filepath.Join(root, "go.mod"), filepath.Join(root, "go.mod.old")); mvErr != nil {
+		t.Fatalf("failed to rename: %v", mvErr)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	var removed []string
+	for _, msg := range sender.Messages() {
+		for _, p := range msg.(sourcesChangedMsg).paths {
+			rel, _ := filepath.Rel(root, p)
+			removed = append(removed, filepath.ToSlash(rel))
+		}
+	}
+	if want := []string{"go.mod", "src/main.go"}; !slices.Equal(removed, want) {
+		t.Errorf("removals reported = %q, want %q", removed, want)
+	}
+}
+
+func TestWatcher_AddGlobs_AbsoluteAndIgnored(t *testing.T) {
+	root, shared := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "node_modules", "lib"), 0o755); err != nil {
+		t.Fatalf("failed to create node_modules: %v", err)
+	}
+
+	sender := &mockSender{}
+	w, err := watcher.StartFileWatcher(nil, sender, watcher.WithDebounce(testDebounce))
+	if err != nil {
+		t.Fatalf("StartFileWatcher failed: %v", err)
+	}
+	defer watcher.Close(w)
+
+	absolute := filepath.ToSlash(shared) + "/*.conf"
+	if addErr := w.AddGlobs(root, "**/*.ts", absolute); addErr != nil {
+		t.Fatalf("AddGlobs failed: %v", addErr)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	files := map[string]bool{
+		filepath.Join(root, "app.ts"):                      true,
+		filepath.Join(shared, "app.conf"):                  true,
+		filepath.Join(root, "node_modules", "lib", "x.ts"): false,
+		filepath.Join(root, "app.conf"):                    false,
+	}
+	for name := range files {
+		if writeErr := os.WriteFile(name, []byte("x"), 0o644); writeErr != nil {
+			t.Fatalf("failed to write %s: %v", name, writeErr)
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	changed := map[string]bool{}
+	for _, msg := range sender.Messages() {
+		for _, p := range msg.(watcher.FileChangedMsg).Paths {
+			changed[p] = true
+		}
+	}
+	for name, want := range files {
+		if changed[name] != want {
+			t.Errorf("change to %s reported = %v, want %v", name, changed[name], want)
+		}
+	}
+}
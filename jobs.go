@@ -0,0 +1,341 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/spool"
+	"github.com/rshep3087/prep/internal/state"
+	"github.com/rshep3087/prep/internal/watcher"
+)
+
+// taskRun is the state of a task run. The model holds the run shown in the
+// output view, and each job holds a run sent to the background.
+type taskRun struct {
+	runID            int                // id of the run, 0 if none
+	runningTask      string             // name of the task being run
+	runningArgs      []string           // arguments passed to the running task
+	runningEnv       []string           // KEY=VALUE environment overrides of the running task
+	runStartedAt     time.Time          // when the running task started
+	runStats         runStats           // stats of the task once it finished
+	previousRun      state.Run          // last recorded run of the same task, zero if none
+	taskRunning      bool               // whether the task is currently running
+	taskStopping     bool               // whether a cancelled task is waiting for its processes to exit
+	taskErr          error              // error from task execution (if any)
+	output           []outputLine       // output lines from the task
+	totalOutputLines int                // total number of output lines received
+	spool            *spool.File        // output lines dropped from the rolling buffer, nil until it overflows
+	stdin            io.WriteCloser     // write end of the running task's stdin, nil once closed
+	partialOutput    string             // unterminated line the task was writing when its output stalled
+	awaitingInput    bool               // whether the task appears to be waiting on input
+	cancelFunc       context.CancelFunc // to cancel the running task
+}
+
+// received appends a line of output to the run. Lines are rendered for
+// display by whoever shows the run.
+func (r taskRun) received(line outputLine, logger *slog.Logger) taskRun {
+	r.totalOutputLines++
+
+	// Output resumed, so whatever the task was waiting for has happened
+	r.partialOutput = ""
+	r.awaitingInput = false
+
+	r.output, r.spool = appendOutput(r.output, r.spool, line, logger)
+	return r
+}
+
+// stalled checks whether a run whose output stalled is waiting on input.
+func (r taskRun) stalled(msg taskIdleMsg) taskRun {
+	if !r.taskRunning || r.stdin == nil {
+		return r
+	}
+	if msg.partial != "" {
+		r.partialOutput = msg.partial
+	}
+	r.awaitingInput = awaitsInput(r.output, msg)
+	return r
+}
+
+// finished records how the run exited.
+func (r taskRun) finished(msg taskDoneMsg, logger *slog.Logger) taskRun {
+	r.taskRunning = false
+	r.taskStopping = false
+	r.taskErr = msg.err
+	r.runStats = msg.stats
+	r.cancelFunc = nil
+	return r.closeStdin(logger)
+}
+
+// closeStdin closes the write end of the task's stdin, which reads EOF.
+func (r taskRun) closeStdin(logger *slog.Logger) taskRun {
+	if r.stdin == nil {
+		return r
+	}
+	if err := r.stdin.Close(); err != nil {
+		logger.Error("error closing task stdin", "error", err)
+	}
+	r.stdin = nil
+	r.awaitingInput = false
+	return r
+}
+
+// record returns the run as it is kept in the history.
+func (r taskRun) record() state.Run {
+	return r.runStats.record(r.runningTask, r.runningArgs, r.runningEnv, r.runStartedAt)
+}
+
+// job is a task run sent to the background.
+type job struct {
+	taskRun
+}
+
+// handleRunMsg handles messages of task runs, routing those of runs sent to
+// the background to their jobs. It reports whether msg was one of them.
+func (m model) handleRunMsg(msg tea.Msg) (model, tea.Cmd, bool) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if msg.ID != m.taskSpinner.ID() {
+			return m, nil, false
+		}
+		// The spinner also animates background jobs in the header
+		if m.taskRunning || m.runningJobs() > 0 {
+			var cmd tea.Cmd
+			m.taskSpinner, cmd = m.taskSpinner.Update(msg)
+			return m, cmd, true
+		}
+		return m, nil, true
+
+	case taskOutputMsg:
+		if msg.run != m.runID {
+			return m.handleJobOutput(msg), nil, true
+		}
+		return m.handleTaskOutput(msg), nil, true
+
+	case taskDoneMsg:
+		if msg.run != m.runID {
+			m, cmd := m.handleJobDone(msg)
+			return m, cmd, true
+		}
+		cancelled := m.taskStopping
+		m = m.recordWatchedRun(msg)
+		m = m.handleTaskDone(msg)
+		if m.watch.rerun {
+			m, cmd := m.rerunWatchedTask()
+			return m, cmd, true
+		}
+		if m.batch.active() {
+			m, cmd := m.continueBatch(msg.err, cancelled)
+			return m, cmd, true
+		}
+		return m, nil, true
+
+	case sourcesChangedMsg:
+		m, cmd := m.handleSourcesChanged(msg)
+		return m, cmd, true
+
+	case taskIdleMsg:
+		if msg.run != m.runID {
+			return m.handleJobIdle(msg), nil, true
+		}
+		return m.handleTaskIdle(msg), nil, true
+
+	case notificationSentMsg:
+		if msg.err != nil {
+			m.logger.Error("error sending notification", "error", msg.err)
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// runningJobs returns the number of background jobs that haven't finished.
+func (m model) runningJobs() int {
+	n := 0
+	for _, j := range m.jobs {
+		if j.taskRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// jobIndex returns the index of the background job of run id, or -1.
+func (m model) jobIndex(id int) int {
+	for i, j := range m.jobs {
+		if j.runID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// detachRun sends the running task to the background and returns to the tables.
+func (m model) detachRun() model {
+	if !m.taskRunning {
+		return m
+	}
+	if m.batch.active() || m.dryRun {
+		m.outputNotice = m.styles.err.Render("only single task runs can go to the background")
+		return m
+	}
+	if m.watch.active() {
+		m.outputNotice = m.styles.err.Render("stop watching before sending the task to the background")
+		return m
+	}
+	m.logger.Debug("sending task to background", "task", m.runningTask, "run", m.runID)
+	m = m.closeStdinInput()
+	m.jobs = append(m.jobs, job{taskRun: m.taskRun})
+
+	// The job owns the run, its spool, stdin and cancel func now
+	m.taskRun = taskRun{}
+	return m.closeOutputView()
+}
+
+// reopenJob shows the output of the oldest background job again. Sending it
+// back with b moves it to the end, so reopening cycles through the jobs.
+func (m model) reopenJob() model {
+	if len(m.jobs) == 0 {
+		return m
+	}
+	j := m.jobs[0]
+	m.jobs = m.jobs[1:]
+	m.logger.Debug("reopening background job", "task", j.runningTask, "run", j.runID)
+
+	m.viewport = m.newOutputViewport()
+	m.showOutput = true
+	m.taskRun = j.taskRun
+	m.streams = showAllStreams
+	m.search = newOutputSearch()
+	m.outputNotice = ""
+
+	// Lines received in the background haven't been rendered yet
+	for i := range m.output {
+		m.output[i].display = m.renderOutputLine(m.output[i])
+	}
+	m = m.refreshOutput()
+	m.viewport.GotoBottom()
+	return m
+}
+
+// handleJobOutput appends output of a background job to its buffer.
+func (m model) handleJobOutput(msg taskOutputMsg) model {
+	i := m.jobIndex(msg.run)
+	if i < 0 {
+		return m
+	}
+	line := outputLine{text: msg.line, stream: msg.stream, at: msg.at}
+	m.jobs[i].taskRun = m.jobs[i].received(line, m.logger)
+	return m
+}
+
+// handleJobIdle checks whether a background job whose output stalled is waiting on input.
+func (m model) handleJobIdle(msg taskIdleMsg) model {
+	if i := m.jobIndex(msg.run); i >= 0 {
+		m.jobs[i].taskRun = m.jobs[i].stalled(msg)
+	}
+	return m
+}
+
+// handleJobDone records a finished background job and notifies the user.
+// When prep is quitting, it quits once the last job has stopped.
+func (m model) handleJobDone(msg taskDoneMsg) (model, tea.Cmd) {
+	i := m.jobIndex(msg.run)
+	if i < 0 {
+		return m, nil
+	}
+	m.jobs[i].taskRun = m.jobs[i].finished(msg, m.logger)
+	finished := m.jobs[i]
+	m.logger.Debug("background job finished", "task", finished.runningTask, "run", finished.runID,
+		"error", msg.err)
+	m = m.recordRun(finished.record())
+
+	if m.quitting {
+		if m.runningJobs() == 0 {
+			return m.quit()
+		}
+		return m, nil
+	}
+	if m.notifier == nil {
+		return m, nil
+	}
+	title, body := jobNotification(finished)
+	return m, m.notifier.Notify(title, body)
+}
+
+// jobNotification returns the title and body of the notification for a finished job.
+func jobNotification(j job) (string, string) {
+	title := "prep: " + j.runningTask
+	switch {
+	case j.runStats.signal != "":
+		return title, fmt.Sprintf("Killed by %s after %s", j.runStats.signal, formatDuration(j.runStats.duration))
+	case j.taskErr != nil && j.runStats.exitCode > 0:
+		return title, fmt.Sprintf("Failed with exit code %d after %s",
+			j.runStats.exitCode, formatDuration(j.runStats.duration))
+	case j.taskErr != nil:
+		return title, fmt.Sprintf("Failed: %v", j.taskErr)
+	}
+	return title, "Completed in " + formatDuration(j.runStats.duration)
+}
+
+// quit exits prep. Background jobs that are still running are stopped first,
+// so their processes don't outlive prep, and prep quits when the last one
+// has exited. Quitting again while they stop quits right away.
+func (m model) quit() (model, tea.Cmd) {
+	if !m.quitting && m.runningJobs() > 0 {
+		m.quitting = true
+		for i := range m.jobs {
+			if m.jobs[i].taskRunning && m.jobs[i].cancelFunc != nil {
+				m.jobs[i].cancelFunc()
+				m.jobs[i].taskStopping = true
+			}
+		}
+		m.logger.Debug("stopping background jobs before quitting", "jobs", m.runningJobs())
+		return m, nil
+	}
+	watcher.Close(m.watcher)
+	m = m.stopWatch()
+	spool.Close(m.spool)
+	for _, j := range m.jobs {
+		spool.Close(j.spool)
+	}
+	return m, tea.Quit
+}
+
+// renderJobsStatus renders the background jobs for the header: a spinner for
+// running jobs, how finished ones exited, and which ones wait on input.
+func (m model) renderJobsStatus() string {
+	if len(m.jobs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		var part string
+		switch {
+		case j.taskStopping:
+			part = m.styles.err.Render(m.taskSpinner.View() + " " + j.runningTask + " stopping")
+		case j.awaitingInput:
+			part = m.styles.inputHint.Render("⌨ " + j.runningTask)
+		case j.taskRunning:
+			part = m.styles.dimTitle.Render(m.taskSpinner.View()+" "+j.runningTask) +
+				m.styles.help.Render(" "+formatDuration(time.Since(j.runStartedAt)))
+		case j.taskErr != nil:
+			part = m.styles.err.Render("✗ " + j.runningTask)
+		default:
+			part = m.styles.success.Render("✓ " + j.runningTask)
+		}
+		parts = append(parts, part)
+	}
+	label := "Jobs: "
+	if m.quitting {
+		label = "Stopping jobs before quitting: "
+	}
+	return m.styles.help.Render(label) + strings.Join(parts, m.styles.help.Render(" · ")) +
+		m.styles.help.Render("  (o to open)")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// recordingNotifier records the notifications it was asked to show.
+type recordingNotifier struct {
+	titles *[]string
+	bodies *[]string
+}
+
+func (n recordingNotifier) Notify(title, body string) tea.Cmd {
+	*n.titles = append(*n.titles, title)
+	*n.bodies = append(*n.bodies, body)
+	return nil
+}
+
+func TestDetachAndReopenJob(t *testing.T) {
+	m := createTestModel(nil, withRunningTask("build", 1))
+	m = m.handleTaskOutput(taskOutputMsg{run: 1, line: "compiling"})
+
+	m = m.detachRun()
+	if m.showOutput || m.taskRunning || m.runID != 0 {
+		t.Fatalf("after detach showOutput = %v, taskRunning = %v, runID = %d", m.showOutput, m.taskRunning, m.runID)
+	}
+	if len(m.jobs) != 1 || m.runningJobs() != 1 {
+		t.Fatalf("jobs = %d, running = %d, want 1 running job", len(m.jobs), m.runningJobs())
+	}
+
+	// Output of the background run goes to its job
+	next, _, ok := m.handleRunMsg(taskOutputMsg{run: 1, line: "linking"})
+	if !ok {
+		t.Fatal("handleRunMsg() did not handle task output")
+	}
+	m = next
+	if m.output != nil {
+		t.Errorf("background output reached the output view: %q", outputTexts(m.output))
+	}
+
+	m = m.reopenJob()
+	if !m.showOutput || !m.taskRunning || m.runID != 1 || m.runningTask != "build" {
+		t.Fatalf("after reopen showOutput = %v, taskRunning = %v, runID = %d, task = %q",
+			m.showOutput, m.taskRunning, m.runID, m.runningTask)
+	}
+	if len(m.jobs) != 0 {
+		t.Errorf("reopened job is still listed in the background")
+	}
+	if got, want := outputTexts(m.output), []string{"compiling", "linking"}; !slices.Equal(got, want) {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if m.output[1].display == "" {
+		t.Error("output received in the background was not rendered")
+	}
+}
+
+func TestHandleJobDone_Notifies(t *testing.T) {
+	var titles, bodies []string
+	m := createTestModel(nil, withRunningTask("test", 1))
+	m.notifier = recordingNotifier{titles: &titles, bodies: &bodies}
+	m = m.detachRun()
+
+	next, _, _ := m.handleRunMsg(taskDoneMsg{run: 1, err: errors.New("exit status 2"), stats: runStats{exitCode: 2}})
+	m = next
+	if m.runningJobs() != 0 || m.jobs[0].taskErr == nil {
+		t.Fatalf("job was not marked as failed: %+v", m.jobs[0])
+	}
+	if !slices.Equal(titles, []string{"prep: test"}) || len(bodies) != 1 {
+		t.Fatalf("notifications = %q %q, want one for the job", titles, bodies)
+	}
+	if want := "Failed with exit code 2 after 0s"; bodies[0] != want {
+		t.Errorf("notification body = %q, want %q", bodies[0], want)
+	}
+}
+
+func TestQuit_StopsJobsFirst(t *testing.T) {
+	cancelled := false
+	m := createTestModel(nil, withRunningTask("serve", 1))
+	m.cancelFunc = func() { cancelled = true }
+	m = m.detachRun()
+
+	m, cmd := m.quit()
+	if cmd != nil || !m.quitting {
+		t.Fatal("quit() should wait for running jobs to stop")
+	}
+	if !cancelled || !m.jobs[0].taskStopping {
+		t.Error("quit() did not stop the running job")
+	}
+
+	next, cmd, _ := m.handleRunMsg(taskDoneMsg{run: 1})
+	if cmd == nil {
+		t.Fatal("prep did not quit after the last job stopped")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok || !next.quitting {
+		t.Error("expected a quit command once the job stopped")
+	}
+}
+
+func TestDetachRun_RefusedWhileWatching(t *testing.T) {
+	m := createTestModel(nil, withWatch(t, "test"))
+
+	m = m.detachRun()
+	if len(m.jobs) != 0 || !m.showOutput || !m.watch.active() {
+		t.Fatalf("jobs = %d, showOutput = %v, want the watched run kept in the output view", len(m.jobs), m.showOutput)
+	}
+	if m.outputNotice == "" {
+		t.Error("detachRun() did not say why the run stays")
+	}
+}
+
+func TestUpdate_BackgroundAndReopenJob(t *testing.T) {
+	m := createTestModel(nil, withRunningTask("build", 1))
+
+	m, _ = pressKeys(t, m, "b")
+	if m.showOutput || len(m.jobs) != 1 {
+		t.Fatalf("after b showOutput = %v with %d jobs, want the run in the background", m.showOutput, len(m.jobs))
+	}
+	next, _ := m.Update(taskOutputMsg{run: 1, line: "linking"})
+	m = next.(model)
+
+	m, _ = pressKeys(t, m, "o")
+	if !m.showOutput || m.runningTask != "build" || len(m.jobs) != 0 {
+		t.Fatalf("after o showOutput = %v, task %q with %d jobs, want build reopened",
+			m.showOutput, m.runningTask, len(m.jobs))
+	}
+	if got := outputTexts(m.output); !slices.Equal(got, []string{"linking"}) {
+		t.Errorf("reopened output = %q, want the lines received in the background", got)
+	}
+}
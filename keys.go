@@ -12,8 +12,17 @@ type tasksKeyMap struct {
 	AltEnter     key.Binding
 	CtrlEnter    key.Binding
 	CtrlAltEnter key.Binding
+	Input        key.Binding
 	Filter       key.Binding
 	Edit         key.Binding
+	New          key.Binding
+	Hidden       key.Binding
+	Favorite     key.Binding
+	Watch        key.Binding
+	Sort         key.Binding
+	Mark         key.Binding
+	RunMarked    key.Binding
+	Preview      key.Binding
 	Quit         key.Binding
 }
 
@@ -44,6 +53,10 @@ func newTasksKeyMap() tasksKeyMap {
 			key.WithKeys("ctrl+shift+enter"),
 			key.WithHelp("Shift+Ctrl+Enter", "interactive + args"),
 		),
+		Input: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "run with input"),
+		),
 		Filter: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "filter"),
@@ -52,6 +65,38 @@ func newTasksKeyMap() tasksKeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit source"),
 		),
+		New: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new task"),
+		),
+		Hidden: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "show hidden"),
+		),
+		Favorite: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "favorite"),
+		),
+		Watch: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "watch"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort: source"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("space"),
+			key.WithHelp("Space", "mark"),
+		),
+		RunMarked: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "run marked"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "preview"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q"),
 			key.WithHelp("q", "quit"),
@@ -61,7 +106,10 @@ func newTasksKeyMap() tasksKeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k tasksKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Tab, k.UpDown, k.Enter, k.AltEnter, k.CtrlEnter, k.CtrlAltEnter, k.Filter, k.Edit, k.Quit}
+	return []key.Binding{
+		k.Tab, k.UpDown, k.Enter, k.AltEnter, k.CtrlEnter, k.CtrlAltEnter, k.Input, k.Filter, k.Edit, k.New,
+		k.Hidden, k.Favorite, k.Watch, k.Sort, k.Mark, k.RunMarked, k.Preview, k.Quit,
+	}
 }
 
 // FullHelp returns keybindings for the expanded help view.
@@ -75,7 +123,10 @@ type toolsKeyMap struct {
 	UpDown key.Binding
 	Add    key.Binding
 	Unuse  key.Binding
+	Change key.Binding
 	Edit   key.Binding
+	Info   key.Binding
+	Filter key.Binding
 	Quit   key.Binding
 }
 
@@ -102,6 +153,18 @@ func newToolsKeyMap() toolsKeyMap {
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit source"),
 		),
+		Info: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "details"),
+		),
+		Change: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "change version"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q"),
 			key.WithHelp("q", "quit"),
@@ -111,7 +174,7 @@ func newToolsKeyMap() toolsKeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k toolsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Tab, k.UpDown, k.Add, k.Unuse, k.Edit, k.Quit}
+	return []key.Binding{k.Tab, k.UpDown, k.Add, k.Unuse, k.Change, k.Edit, k.Info, k.Filter, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view.
@@ -126,6 +189,7 @@ type envVarsKeyMap struct {
 	ShowOne key.Binding
 	ShowAll key.Binding
 	HideAll key.Binding
+	Filter  key.Binding
 	Quit    key.Binding
 }
 
@@ -152,6 +216,10 @@ func newEnvVarsKeyMap() envVarsKeyMap {
 			key.WithKeys("h"),
 			key.WithHelp("h", "hide all"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("q"),
 			key.WithHelp("q", "quit"),
@@ -161,7 +229,7 @@ func newEnvVarsKeyMap() envVarsKeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k envVarsKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Tab, k.UpDown, k.ShowOne, k.ShowAll, k.HideAll, k.Quit}
+	return []key.Binding{k.Tab, k.UpDown, k.ShowOne, k.ShowAll, k.HideAll, k.Filter, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view.
@@ -171,15 +239,62 @@ func (k envVarsKeyMap) FullHelp() [][]key.Binding {
 
 // outputKeyMap defines key bindings for the output view.
 type outputKeyMap struct {
-	Cancel key.Binding
-	Scroll key.Binding
-	Close  key.Binding
-	Wrap   key.Binding
+	Cancel     key.Binding
+	Scroll     key.Binding
+	Close      key.Binding
+	Wrap       key.Binding
+	Search     key.Binding
+	NextMatch  key.Binding
+	FirstError key.Binding
+	Save       key.Binding
+	Copy       key.Binding
+	ANSI       key.Binding
+	Streams    key.Binding
+	Timestamps key.Binding
+	Input      key.Binding
+	Background key.Binding
+	Watch      key.Binding
 }
 
 // newOutputKeyMap creates a new outputKeyMap.
 // running indicates if a task is currently running.
 func newOutputKeyMap(running bool) outputKeyMap {
+	search := key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	)
+	nextMatch := key.NewBinding(
+		key.WithKeys("n", "N"),
+		key.WithHelp("n/N", "next/prev match"),
+	)
+	firstError := key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "first error"),
+	)
+	save := key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "save"),
+	)
+	copyOutput := key.NewBinding(
+		key.WithKeys("y", "Y"),
+		key.WithHelp("y/Y", "copy all/visible"),
+	)
+	ansiCodes := key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "keep/strip colors"),
+	)
+	streams := key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "stdout/stderr"),
+	)
+	timestamps := key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "timestamps"),
+	)
+	watch := key.NewBinding(
+		key.WithKeys("W"),
+		key.WithHelp("W", "watch"),
+	)
 	if running {
 		return outputKeyMap{
 			Cancel: key.NewBinding(
@@ -194,6 +309,23 @@ func newOutputKeyMap(running bool) outputKeyMap {
 				key.WithKeys("w"),
 				key.WithHelp("w", "wrap"),
 			),
+			Search:     search,
+			NextMatch:  nextMatch,
+			FirstError: firstError,
+			Save:       save,
+			Copy:       copyOutput,
+			ANSI:       ansiCodes,
+			Streams:    streams,
+			Timestamps: timestamps,
+			Watch:      watch,
+			Input: key.NewBinding(
+				key.WithKeys("i"),
+				key.WithHelp("i", "send input"),
+			),
+			Background: key.NewBinding(
+				key.WithKeys("b"),
+				key.WithHelp("b", "background"),
+			),
 		}
 	}
 	return outputKeyMap{
@@ -213,15 +345,30 @@ func newOutputKeyMap(running bool) outputKeyMap {
 			key.WithKeys("ctrl+c"),
 			key.WithHelp("Ctrl+C", "quit"),
 		),
+		Search:     search,
+		NextMatch:  nextMatch,
+		FirstError: firstError,
+		Save:       save,
+		Copy:       copyOutput,
+		ANSI:       ansiCodes,
+		Streams:    streams,
+		Timestamps: timestamps,
+		Watch:      watch,
 	}
 }
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k outputKeyMap) ShortHelp() []key.Binding {
 	if k.Close.Enabled() {
-		return []key.Binding{k.Close, k.Scroll, k.Wrap, k.Cancel}
+		return []key.Binding{
+			k.Close, k.Scroll, k.Wrap, k.Streams, k.Timestamps, k.Search, k.NextMatch, k.FirstError,
+			k.Save, k.Copy, k.ANSI, k.Watch, k.Cancel,
+		}
+	}
+	return []key.Binding{
+		k.Cancel, k.Background, k.Input, k.Scroll, k.Wrap, k.Streams, k.Timestamps, k.Search, k.NextMatch, k.FirstError,
+		k.Save, k.Copy, k.ANSI, k.Watch,
 	}
-	return []key.Binding{k.Cancel, k.Scroll, k.Wrap}
 }
 
 // FullHelp returns keybindings for the expanded help view.
@@ -231,8 +378,10 @@ func (k outputKeyMap) FullHelp() [][]key.Binding {
 
 // argInputKeyMap defines key bindings for the argument input view.
 type argInputKeyMap struct {
-	Enter  key.Binding
-	Cancel key.Binding
+	Enter   key.Binding
+	Preview key.Binding
+	Switch  key.Binding
+	Cancel  key.Binding
 }
 
 // newArgInputKeyMap creates a new argInputKeyMap.
@@ -242,6 +391,14 @@ func newArgInputKeyMap() argInputKeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("Enter", "run"),
 		),
+		Preview: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("Ctrl+P", "preview"),
+		),
+		Switch: key.NewBinding(
+			key.WithKeys("tab", "shift+tab"),
+			key.WithHelp("Tab", "args/env"),
+		),
 		Cancel: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("Esc", "cancel"),
@@ -251,7 +408,7 @@ func newArgInputKeyMap() argInputKeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view.
 func (k argInputKeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Enter, k.Cancel}
+	return []key.Binding{k.Enter, k.Preview, k.Switch, k.Cancel}
 }
 
 // FullHelp returns keybindings for the expanded help view.
@@ -259,6 +416,198 @@ func (k argInputKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{k.ShortHelp()}
 }
 
+// batchKeyMap defines key bindings for choosing how marked tasks run.
+type batchKeyMap struct {
+	Sequence  key.Binding
+	KeepGoing key.Binding
+	Parallel  key.Binding
+	Cancel    key.Binding
+}
+
+// newBatchKeyMap creates a new batchKeyMap.
+func newBatchKeyMap() batchKeyMap {
+	return batchKeyMap{
+		Sequence: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "in sequence, stop on failure"),
+		),
+		KeepGoing: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "in sequence, continue on failure"),
+		),
+		Parallel: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "in parallel"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k batchKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Sequence, k.KeepGoing, k.Parallel, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k batchKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// toolDetailKeyMap defines key bindings for the tool detail pane.
+type toolDetailKeyMap struct {
+	CopyPath key.Binding
+	Edit     key.Binding
+	Close    key.Binding
+}
+
+// newToolDetailKeyMap creates a new toolDetailKeyMap.
+func newToolDetailKeyMap() toolDetailKeyMap {
+	return toolDetailKeyMap{
+		CopyPath: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy install path"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit config"),
+		),
+		Close: key.NewBinding(
+			key.WithKeys("esc", "q"),
+			key.WithHelp("Esc", "close"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k toolDetailKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.CopyPath, k.Edit, k.Close}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k toolDetailKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// toolListKeyMap defines the key bindings the tool list of the picker adds to its own.
+type toolListKeyMap struct {
+	Refresh key.Binding
+}
+
+// newToolListKeyMap creates a new toolListKeyMap.
+func newToolListKeyMap() toolListKeyMap {
+	return toolListKeyMap{
+		Refresh: newRefreshBinding(),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k toolListKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Refresh}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k toolListKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// versionListKeyMap defines the key bindings the version list adds to its own.
+type versionListKeyMap struct {
+	Prereleases key.Binding
+	Refresh     key.Binding
+}
+
+// newVersionListKeyMap creates a new versionListKeyMap.
+func newVersionListKeyMap() versionListKeyMap {
+	return versionListKeyMap{
+		Prereleases: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "prereleases"),
+		),
+		Refresh: newRefreshBinding(),
+	}
+}
+
+// newRefreshBinding creates the binding that loads a picker list again, bypassing the cache.
+func newRefreshBinding() key.Binding {
+	return key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "refresh"),
+	)
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k versionListKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Prereleases, k.Refresh}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k versionListKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// customToolKeyMap defines key bindings for the custom tool input of the tool picker.
+type customToolKeyMap struct {
+	Load key.Binding
+	Back key.Binding
+}
+
+// newCustomToolKeyMap creates a new customToolKeyMap.
+func newCustomToolKeyMap() customToolKeyMap {
+	return customToolKeyMap{
+		Load: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "load versions"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "back"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k customToolKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Load, k.Back}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k customToolKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// confirmKeyMap defines key bindings for confirming a dangerous run.
+type confirmKeyMap struct {
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+// newConfirmKeyMap creates a new confirmKeyMap.
+func newConfirmKeyMap() confirmKeyMap {
+	return confirmKeyMap{
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "run"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k confirmKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k confirmKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
 // filterKeyMap defines key bindings for the filter input view.
 type filterKeyMap struct {
 	Enter  key.Binding
@@ -266,11 +615,16 @@ type filterKeyMap struct {
 }
 
 // newFilterKeyMap creates a new filterKeyMap.
-func newFilterKeyMap() filterKeyMap {
+// runsTask indicates if Enter runs the selected task rather than applying the filter.
+func newFilterKeyMap(runsTask bool) filterKeyMap {
+	enterHelp := "apply"
+	if runsTask {
+		enterHelp = "run selected"
+	}
 	return filterKeyMap{
 		Enter: key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("Enter", "run selected"),
+			key.WithHelp("Enter", enterHelp),
 		),
 		Cancel: key.NewBinding(
 			key.WithKeys("esc"),
@@ -288,3 +642,136 @@ func (k filterKeyMap) ShortHelp() []key.Binding {
 func (k filterKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{k.ShortHelp()}
 }
+
+// searchKeyMap defines key bindings for the output search input.
+type searchKeyMap struct {
+	Confirm key.Binding
+	Cancel  key.Binding
+}
+
+// newSearchKeyMap creates a new searchKeyMap.
+func newSearchKeyMap() searchKeyMap {
+	return searchKeyMap{
+		Confirm: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "keep matches"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "clear search"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k searchKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k searchKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// saveKeyMap defines key bindings for the save output path input.
+type saveKeyMap struct {
+	Save   key.Binding
+	Cancel key.Binding
+}
+
+// newSaveKeyMap creates a new saveKeyMap.
+func newSaveKeyMap() saveKeyMap {
+	return saveKeyMap{
+		Save: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "save"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k saveKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Save, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k saveKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// stdinKeyMap defines key bindings for the input line of a running task.
+type stdinKeyMap struct {
+	Send   key.Binding
+	EOF    key.Binding
+	Cancel key.Binding
+}
+
+// newStdinKeyMap creates a new stdinKeyMap.
+func newStdinKeyMap() stdinKeyMap {
+	return stdinKeyMap{
+		Send: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "send line"),
+		),
+		EOF: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("Ctrl+D", "end input"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k stdinKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Send, k.EOF, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k stdinKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// wizardKeyMap defines key bindings for the new task wizard.
+type wizardKeyMap struct {
+	Next    key.Binding
+	Newline key.Binding
+	Cancel  key.Binding
+}
+
+// newWizardKeyMap creates a new wizardKeyMap.
+// multiline indicates if the current step accepts a multi-line script.
+func newWizardKeyMap(multiline bool) wizardKeyMap {
+	k := wizardKeyMap{
+		Next: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("Enter", "next"),
+		),
+		Newline: key.NewBinding(
+			key.WithKeys("alt+enter"),
+			key.WithHelp("Alt+Enter", "newline"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "cancel"),
+		),
+	}
+	k.Newline.SetEnabled(multiline)
+	return k
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view.
+func (k wizardKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Next, k.Newline, k.Cancel}
+}
+
+// FullHelp returns keybindings for the expanded help view.
+func (k wizardKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
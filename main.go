@@ -10,9 +10,14 @@ import (
 	"os"
 
 	"charm.land/bubbles/v2/help"
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/textarea"
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/state"
 )
 
 const defaultHelpWidth = 80
@@ -30,9 +35,31 @@ func run(_ context.Context, args []string, stdin io.Reader, stdout, stderr io.Wr
 	fs.SetOutput(stderr)
 	debug := fs.Bool("debug", false, "enable debug logging to debug.log")
 	editorFlag := fs.String("editor", "", "editor command for editing source files (overrides $EDITOR)")
+	stopGrace := fs.Duration("stop-grace", defaultStopGrace,
+		"how long a cancelled task gets to exit after it is interrupted before it is killed")
+	notifyFlag := fs.String("notify", "bell",
+		"how to tell when a background task finishes: "+notifierNames)
+	confirmFlag := fs.String("confirm", defaultConfirmPatterns,
+		"comma separated task name globs that ask to type the task name before running")
+	miseFlag := fs.String("mise", loader.DefaultBinary, "mise binary to run, for pinned or wrapped installs")
+	miseFlagsFlag := fs.String("mise-flags", "",
+		`global flags passed to every mise command, like "--cd ../api --verbose"`)
 	if err := fs.Parse(args[1:]); err != nil {
 		return err
 	}
+	notify, err := newNotifier(*notifyFlag)
+	if err != nil {
+		return err
+	}
+	confirmPatterns, err := parseConfirmPatterns(*confirmFlag)
+	if err != nil {
+		return err
+	}
+	miseFlags, err := loader.ParseFlags(*miseFlagsFlag)
+	if err != nil {
+		return err
+	}
+	mise := loader.NewClient(execRunner{}, *miseFlag, miseFlags)
 
 	// Determine editor: flag takes precedence over env var, fallback to "vi"
 	editor := *editorFlag
@@ -68,53 +95,132 @@ func run(_ context.Context, args []string, stdin io.Reader, stdout, stderr io.Wr
 		return fmt.Errorf("get user home directory: %w", homeDirErr)
 	}
 
+	// Load favorites and run history, falling back to in-memory state
+	store, storeErr := openStateStore()
+	if storeErr != nil {
+		logger.Error("error loading state, favorites and history won't be saved", "error", storeErr)
+	}
+
+	// Cache the registry and remote versions, or run mise every time without a cache dir
+	cacheDir, cacheErr := loader.DefaultCacheDir()
+	if cacheErr != nil {
+		logger.Error("error finding cache directory, tool lists won't be cached", "error", cacheErr)
+	}
+
 	// Initialize argument input textinput
 	ti := textinput.New()
 	ti.Placeholder = "Enter arguments..."
 	ti.CharLimit = 500
 	ti.SetWidth(defaultInputWidth)
 
-	// Initialize filter input textinput
-	filterInput := textinput.New()
-	filterInput.Placeholder = "Filter tasks..."
-	filterInput.CharLimit = 100
-	filterInput.SetWidth(defaultInputWidth)
+	// Initialize environment overrides input of the argument dialog
+	envInput := textinput.New()
+	envInput.Placeholder = "LOG_LEVEL=debug CI=1"
+	envInput.CharLimit = 500
+	envInput.SetWidth(defaultInputWidth)
+
+	// Initialize save output path input
+	saveInput := textinput.New()
+	saveInput.CharLimit = 500
+	saveInput.SetWidth(defaultInputWidth)
+
+	// Initialize input line for running tasks
+	stdinInput := textinput.New()
+	stdinInput.Prompt = ""
+	stdinInput.CharLimit = 500
+	stdinInput.SetWidth(defaultInputWidth)
+
+	// Initialize confirmation input of dangerous runs
+	confirmInput := textinput.New()
+	confirmInput.CharLimit = 200
+	confirmInput.SetWidth(defaultInputWidth)
+
+	// Initialize custom tool input of the tool picker
+	customToolInput := textinput.New()
+	customToolInput.Placeholder = "npm:prettier"
+	customToolInput.CharLimit = 200
+	customToolInput.SetWidth(defaultInputWidth)
+
+	// Initialize new task wizard inputs
+	wizardInput := textinput.New()
+	wizardInput.CharLimit = 200
+	wizardInput.SetWidth(defaultInputWidth)
+
+	wizardScript := textarea.New()
+	wizardScript.Placeholder = "echo hello"
+	wizardScript.ShowLineNumbers = false
+	wizardScript.SetWidth(defaultInputWidth)
+	wizardScript.SetHeight(wizardScriptHeight)
+	// Enter advances the wizard, so newlines are inserted with Alt+Enter
+	wizardScript.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter"))
 
 	m := &model{
-		tasksTable:     newTable(getTasksTableConfig(), nil, true),
-		toolsTable:     newTable(getToolsTableConfig(), nil, false),
-		envVarsTable:   newTable(getEnvVarsTableConfig(), nil, false),
-		tasksLoading:   true,
-		toolsLoading:   true,
-		envVarsLoading: true,
-		argInput:       ti,
-		taskSpinner:    spinner.New(),
-		runner:         execRunner{},
-		styles:         newStyles(),
-		logger:         logger,
-		editor:         editor,
-		cwd:            cwd,
-		homeDir:        homeDir,
-		tasksHelp:      initHelpModel(),
-		envVarsHelp:    initHelpModel(),
-		toolsHelp:      initHelpModel(),
-		outputHelp:     initHelpModel(),
-		argInputHelp:   initHelpModel(),
-		filterHelp:     initHelpModel(),
-		tasksKeys:      newTasksKeyMap(),
-		envVarsKeys:    newEnvVarsKeyMap(),
-		toolsKeys:      newToolsKeyMap(),
-		outputKeys:     newOutputKeyMap(false),
-		argInputKeys:   newArgInputKeyMap(),
-		filterKeys:     newFilterKeyMap(),
-		filterInput:    filterInput,
+		tasksTable:      newTable(getTasksTableConfig(), nil, true),
+		toolsTable:      newTable(getToolsTableConfig(), nil, false),
+		envVarsTable:    newTable(getEnvVarsTableConfig(), nil, false),
+		tasksLoading:    true,
+		toolsLoading:    true,
+		envVarsLoading:  true,
+		argInput:        ti,
+		envInput:        envInput,
+		taskSpinner:     spinner.New(),
+		mise:            mise,
+		styles:          newStyles(),
+		logger:          logger,
+		editor:          editor,
+		stopGrace:       *stopGrace,
+		notifier:        notify,
+		confirmPatterns: confirmPatterns,
+		store:           store,
+		cacheDir:        cacheDir,
+		cwd:             cwd,
+		homeDir:         homeDir,
+		tasksHelp:       initHelpModel(),
+		envVarsHelp:     initHelpModel(),
+		toolsHelp:       initHelpModel(),
+		outputHelp:      initHelpModel(),
+		argInputHelp:    initHelpModel(),
+		filterHelp:      initHelpModel(),
+		tasksKeys:       newTasksKeyMap(),
+		envVarsKeys:     newEnvVarsKeyMap(),
+		toolsKeys:       newToolsKeyMap(),
+		outputKeys:      newOutputKeyMap(false),
+		argInputKeys:    newArgInputKeyMap(),
+		filterKeys:      newFilterKeyMap(true),
+		filters: [focusSectionCount]tableFilter{
+			focusTasks:   newTableFilter("Filter tasks... (src:<path> matches source files)"),
+			focusTools:   newTableFilter("Filter tools by name, version or source..."),
+			focusEnvVars: newTableFilter("Filter env vars by name or shown value..."),
+		},
+		search:          newOutputSearch(),
+		saveInput:       saveInput,
+		stdinInput:      stdinInput,
+		confirmInput:    confirmInput,
+		customToolInput: customToolInput,
+		wizardInput:     wizardInput,
+		wizardScript:    wizardScript,
+		wizardHelp:      initHelpModel(),
 	}
 	program := tea.NewProgram(m, tea.WithInput(stdin), tea.WithOutput(stdout))
 	m.sender = program // *tea.Program implements messageSender
-	_, err := program.Run()
+	_, err = program.Run()
 	return err
 }
 
+// openStateStore opens the state file at its default location. On error it
+// returns an in-memory store alongside the error so prep stays usable.
+func openStateStore() (*state.Store, error) {
+	path, err := state.DefaultPath()
+	if err == nil {
+		var store *state.Store
+		if store, err = state.Open(path); err == nil {
+			return store, nil
+		}
+	}
+	memory, _ := state.Open("")
+	return memory, err
+}
+
 func main() {
 	ctx := context.Background()
 	err := run(ctx, os.Args, os.Stdin, os.Stdout, os.Stderr)
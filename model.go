@@ -5,29 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"os/exec"
+	"strings"
 	"time"
 
 	"charm.land/bubbles/v2/help"
 	"charm.land/bubbles/v2/list"
 	"charm.land/bubbles/v2/spinner"
 	"charm.land/bubbles/v2/table"
+	"charm.land/bubbles/v2/textarea"
 	"charm.land/bubbles/v2/textinput"
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
-	"github.com/fsnotify/fsnotify"
 
 	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/scaffold"
+	"github.com/rshep3087/prep/internal/state"
 	"github.com/rshep3087/prep/internal/watcher"
 )
 
-// commandRunner runs commands.
-type commandRunner interface {
-	Run(ctx context.Context, args ...string) ([]byte, error)
-}
-
-// execRunner implements commandRunner using os/exec.
+// execRunner implements loader.CommandRunner using os/exec.
 type execRunner struct{}
 
 // ErrNoCommand is returned when no command is provided to Run.
@@ -49,12 +48,105 @@ type messageSender interface {
 
 // taskOutputMsg is sent when a running task produces output.
 type taskOutputMsg struct {
-	line string
+	run    int // id of the run that produced the output
+	line   string
+	stream outputStream
+	at     time.Time
+}
+
+// outputStream identifies the stream a line of task output was written to.
+type outputStream int
+
+const (
+	streamStdout outputStream = iota
+	streamStderr
+)
+
+// outputLine is a line of task output tagged with its stream and when it was received.
+type outputLine struct {
+	text    string
+	stream  outputStream
+	at      time.Time
+	display string // text as shown: styled by stream and prefixed with the timestamp if enabled
+}
+
+// streamFilter selects which output streams the output view shows.
+type streamFilter int
+
+const (
+	showAllStreams streamFilter = iota
+	showStdoutOnly
+	showStderrOnly
+	streamFilterCount // total number of stream filters for cycling
+)
+
+// String returns the display name of the stream filter.
+func (f streamFilter) String() string {
+	switch f {
+	case showStdoutOnly:
+		return "stdout"
+	case showStderrOnly:
+		return "stderr"
+	case showAllStreams, streamFilterCount:
+	}
+	return "all"
+}
+
+// shows reports whether lines from stream pass the filter.
+func (f streamFilter) shows(stream outputStream) bool {
+	switch f {
+	case showStdoutOnly:
+		return stream == streamStdout
+	case showStderrOnly:
+		return stream == streamStderr
+	case showAllStreams, streamFilterCount:
+	}
+	return true
 }
 
 // taskDoneMsg is sent when a task finishes executing.
 type taskDoneMsg struct {
-	err error
+	run   int // id of the run that finished
+	err   error
+	stats runStats
+}
+
+// runStats describes how a task process exited and the resources it used.
+type runStats struct {
+	exitCode int           // -1 if the process never exited normally
+	signal   string        // signal that terminated the process, if any
+	duration time.Duration // wall clock time
+	cpuTime  time.Duration // user and system CPU time
+	peakRSS  int64         // peak resident set size in bytes, 0 where unavailable
+}
+
+// newRunStats collects the stats of a finished process. ps is nil if the process never started.
+func newRunStats(ps *os.ProcessState, duration time.Duration) runStats {
+	if ps == nil {
+		return runStats{exitCode: -1, duration: duration}
+	}
+	stats := runStats{
+		exitCode: ps.ExitCode(),
+		duration: duration,
+		cpuTime:  ps.UserTime() + ps.SystemTime(),
+	}
+	stats.signal, stats.peakRSS = processStats(ps)
+	return stats
+}
+
+// record returns the run of task with these stats for the run history.
+func (s runStats) record(task string, args, env []string, startedAt time.Time) state.Run {
+	return state.Run{
+		Task:      task,
+		Args:      args,
+		Env:       env,
+		StartedAt: startedAt,
+		ExitCode:  s.exitCode,
+		Signal:    s.signal,
+		Duration:  s.duration,
+		CPUTime:   s.cpuTime,
+		PeakRSS:   s.peakRSS,
+	}
 }
 
 // editorClosedMsg is sent when the external editor closes.
@@ -64,7 +156,41 @@ type editorClosedMsg struct {
 
 // interactiveTaskClosedMsg is sent when an interactive task finishes.
 type interactiveTaskClosedMsg struct {
-	taskName string
+	taskName  string
+	args      []string
+	env       []string
+	startedAt time.Time
+	stats     runStats
+	err       error
+}
+
+// taskSortMode controls how tasks are ordered below the pinned favorites.
+type taskSortMode int
+
+const (
+	sortBySource   taskSortMode = iota // source priority, then name
+	sortByRecent                       // most recently run first
+	sortByFrequent                     // most often run first
+	sortModeCount                      // total number of sort modes for cycling
+)
+
+// String returns the name shown in the help view.
+func (s taskSortMode) String() string {
+	switch s {
+	case sortByRecent:
+		return "recent"
+	case sortByFrequent:
+		return "frequent"
+	case sortBySource, sortModeCount:
+	}
+	return "source"
+}
+
+// taskCreatedMsg is sent when the new task wizard finishes writing a task.
+type taskCreatedMsg struct {
+	name     string
+	path     string
+	fileTask bool
 	err      error
 }
 
@@ -74,6 +200,8 @@ type pickerState int
 const (
 	pickerClosed          pickerState = iota // picker not showing
 	pickerSelectTool                         // showing tool list
+	pickerSelectBackend                      // showing backend list of a tool with several
+	pickerEnterCustomTool                    // typing a tool that isn't in the registry
 	pickerLoadingVersions                    // loading versions for selected tool
 	pickerSelectVersion                      // showing version list
 	pickerSelectConfig                       // showing config file list
@@ -82,8 +210,9 @@ const (
 
 // toolItem represents a tool in the picker list.
 type toolItem struct {
-	name    string
-	backend string
+	name     string
+	backend  string
+	backends []string // every backend of the tool, the default first
 }
 
 // FilterValue implements list.Item.
@@ -93,21 +222,60 @@ func (t toolItem) FilterValue() string { return t.name }
 func (t toolItem) Title() string { return t.name }
 
 // Description implements list.DefaultItem.
-func (t toolItem) Description() string { return t.backend }
+func (t toolItem) Description() string {
+	if len(t.backends) > 1 {
+		return strings.Join(t.backends, ", ")
+	}
+	return t.backend
+}
+
+// backendItem represents a backend of the selected tool in the picker list.
+type backendItem struct {
+	tool      string
+	backend   string
+	isDefault bool
+}
+
+// FilterValue implements list.Item.
+func (b backendItem) FilterValue() string { return b.backend }
+
+// Title implements list.DefaultItem.
+func (b backendItem) Title() string { return b.backend }
+
+// Description implements list.DefaultItem.
+func (b backendItem) Description() string {
+	if b.isDefault {
+		return "default"
+	}
+	return ""
+}
 
 // versionItem represents a version in the picker list.
 type versionItem struct {
-	version string
+	version   string
+	fuzzy     bool   // resolved by mise to the newest matching release
+	grouped   bool   // listed below the heading of its series
+	requested bool   // the version the tool is requested at
+	note      string // what the version resolves to and whether the tool uses it
 }
 
 // FilterValue implements list.Item.
 func (v versionItem) FilterValue() string { return v.version }
 
 // Title implements list.DefaultItem.
-func (v versionItem) Title() string { return v.version }
+func (v versionItem) Title() string {
+	title := v.version
+	if v.grouped {
+		title = "  " + title
+	}
+	if v.requested {
+		title = "● " + title
+	}
+	return title
+}
 
 // Description implements list.DefaultItem.
-func (v versionItem) Description() string { return "" }
+func (v versionItem) Description() string { return v.note }
 
 // configItem represents a config file in the picker list.
 type configItem struct {
@@ -123,6 +291,44 @@ func (c configItem) Title() string { return c.path }
 // Description implements list.DefaultItem.
 func (c configItem) Description() string { return "" }
 
+// wizardStep represents the current step of the new task wizard.
+type wizardStep int
+
+const (
+	wizardClosed      wizardStep = iota // wizard not showing
+	wizardName                          // entering the task name
+	wizardDescription                   // entering the description
+	wizardRun                           // entering the run command or script
+	wizardDepends                       // entering dependencies
+	wizardTarget                        // choosing where to write the task
+	wizardWriting                       // writing the task to disk
+)
+
+// targetItem represents a destination for a new task in the wizard.
+type targetItem struct {
+	path     string // config file to append to, or the file task to create
+	fileTask bool   // whether to write an executable file task
+}
+
+// FilterValue implements list.Item.
+func (t targetItem) FilterValue() string { return t.path }
+
+// Title implements list.DefaultItem.
+func (t targetItem) Title() string {
+	if t.fileTask {
+		return "File task: " + formatSourcePath(t.path)
+	}
+	return formatSourcePath(t.path)
+}
+
+// Description implements list.DefaultItem.
+func (t targetItem) Description() string {
+	if t.fileTask {
+		return "executable script with #MISE header comments"
+	}
+	return "TOML table in config file"
+}
+
 type model struct {
 	tasksTable     table.Model
 	toolsTable     table.Model
@@ -134,51 +340,87 @@ type model struct {
 	tasksLoading   bool
 	toolsLoading   bool
 	envVarsLoading bool
+	showHidden     bool         // whether tasks marked hide = true are listed
+	taskSort       taskSortMode // ordering applied below pinned favorites
 	err            error
+	notice         string // why the last key did nothing, shown until the next key press
 
 	// Mise info for header
 	miseVersion string
 
 	// Task execution state
-	showOutput       bool               // whether to show the output viewport
-	runningTask      string             // name of the task being run
-	taskRunning      bool               // whether a task is currently running
-	taskSpinner      spinner.Model      // animated spinner for running tasks
-	taskErr          error              // error from task execution (if any)
-	output           []string           // output lines from the task
-	totalOutputLines int                // total number of output lines received
-	viewport         viewport.Model     // scrollable viewport for output
-	wrapOutput       bool               // whether word wrapping is enabled for output
-	cancelFunc       context.CancelFunc // to cancel the running task
+	taskRun                          // the run shown in the output view
+	showOutput       bool            // whether to show the output viewport
+	lastRunID        int             // id of the last run started
+	stopGrace        time.Duration   // how long a cancelled task gets to exit before it is killed
+	taskSpinner      spinner.Model   // animated spinner for running tasks
+	streams          streamFilter    // output streams shown in the output view
+	showTimestamps   bool            // whether output lines are prefixed with the time they were received
+	viewport         viewport.Model  // scrollable viewport for output
+	wrapOutput       bool            // whether word wrapping is enabled for output
+	search           outputSearch    // search within the output
+	keepANSI         bool            // keep ANSI escape codes when saving or copying output
+	saveInput        textinput.Model // path input for saving output
+	saveInputActive  bool            // whether the save path is being edited
+	outputNotice     string          // result of the last save or copy, shown until the next key press
+	stdinInput       textinput.Model // input line for the running task's stdin
+	stdinInputActive bool            // whether input for the task is being typed
+	jobs             []job           // runs sent to the background, oldest first
+	notifier         notifier        // tells the user when a background job finishes, nil for none
+	quitting         bool            // whether prep quits once its background jobs have stopped
+	watch            taskWatch       // re-runs the task in the output view when its sources change
+	batch            taskBatch       // marked tasks run in the output view, if it shows a batch
+	dryRun           bool            // whether the output view previews a task instead of running it
+	markedTasks      []string        // tasks marked for a batch, in the order they were marked
+	lastWatchID      int             // id of the last watch started
 	windowWidth      int
 	windowHeight     int
 
 	// Task arguments state
 	argInputActive      bool            // whether argument input mode is active
 	argInput            textinput.Model // text input for task arguments
+	envInput            textinput.Model // text input for KEY=VALUE environment overrides
+	envInputFocused     bool            // whether the environment field has focus instead of the arguments
+	argInputErr         error           // why the environment overrides can't be used
 	argInputTask        string          // task name that arguments are for
 	argInputInteractive bool            // whether argument input is for interactive execution
+	batchPromptActive   bool            // whether prep asks how to run the marked tasks
+
+	// Confirmation of dangerous runs
+	confirmPatterns []string        // task name globs that need confirming
+	confirm         pendingConfirm  // run waiting for the task name to be typed
+	confirmInput    textinput.Model // where the task name is typed
+	confirmErr      string          // shown when the typed name doesn't match
 
 	// Dependencies (DIP)
-	runner commandRunner // for running commands
-	sender messageSender // for sending messages to the program
-	styles styles        // UI styles
-	logger *slog.Logger  // for logging
-	editor string        // editor command for editing source files
+	mise   *loader.Client // runs mise with the configured binary and global flags
+	sender messageSender  // for sending messages to the program
+	styles styles         // UI styles
+	logger *slog.Logger   // for logging
+	editor string         // editor command for editing source files
+	store  *state.Store   // persists favorites and run history
 
 	// File watching state
-	watcher     *fsnotify.Watcher // watches config files for changes
-	configPaths []string          // paths being watched
-	lastReload  time.Time         // for debouncing file change events
+	watcher     *watcher.Watcher // watches config files and task sources for changes
+	configPaths []string         // config file paths reported by mise
 
 	// Tool picker state
 	pickerState     pickerState // current picker state
 	toolList        list.Model  // list of available tools
 	versionList     list.Model  // list of versions for selected tool
-	configList      list.Model  // list of config files for installation target
-	selectedTool    string      // tool selected in first step
-	selectedVersion string      // version selected in second step
-	versionsLoading bool        // loading versions
+	backendList     list.Model  // list of backends for the selected tool, empty if it has one
+	customTool      bool        // whether the selected tool was typed rather than picked
+	customToolInput textinput.Model
+	customToolErr   string         // shown when the typed tool is invalid or has no versions
+	configList      list.Model     // list of config files for installation target
+	selectedTool    string         // tool selected in first step
+	selectedVersion string         // version selected in second step
+	versionsLoading bool           // loading versions
+	pickerChange    loader.Tool    // tool whose version is changed in place, if any
+	versionChoices  versionChoices // versions listed for the selected tool
+	cacheDir        string         // where the registry and remote versions are cached, empty for none
+
+	toolDetail toolDetailPane // details of the selected tool, when open
 
 	// Cached directory paths for source priority sorting
 	cwd     string
@@ -200,20 +442,34 @@ type model struct {
 	argInputKeys argInputKeyMap
 	filterKeys   filterKeyMap
 
-	// Task filter state
-	filterActive  bool            // whether filter mode is active
-	filterInput   textinput.Model // text input for filtering tasks
-	filteredTasks []loader.Task   // tasks matching current filter
+	// New task wizard state
+	wizardStep    wizardStep        // current wizard step
+	wizardInput   textinput.Model   // single-line input for name, description and depends
+	wizardScript  textarea.Model    // multi-line input for the run script
+	wizardTargets list.Model        // destinations for the new task
+	wizardSpec    scaffold.TaskSpec // answers collected so far
+	wizardErr     error             // validation or write error shown in the wizard
+	wizardHelp    help.Model
+	wizardKeys    wizardKeyMap
+
+	// Table filter state, the filtered slices mirror the rows shown in each table
+	filters         [focusSectionCount]tableFilter // "/" filter of each table, indexed by focus section
+	filteredTasks   []loader.Task                  // tasks shown in the table (all tasks when no filter is applied)
+	filteredTools   []loader.Tool                  // tools shown in the table
+	filteredEnvVars []loader.EnvVar                // env vars shown in the table
+	taskMatches     map[string]taskMatch           // filter matches by task name, for highlighting
+	toolMatches     map[string]rowMatch            // filter matches by toolMatchKey
+	envVarMatches   map[string]rowMatch            // filter matches by env var name
 }
 
 func (m model) Init() tea.Cmd {
 	ctx := context.Background()
 	return tea.Batch(
-		loader.LoadMiseTasks(ctx, m.runner),
-		loader.LoadMiseTools(ctx, m.runner),
-		loader.LoadMiseEnvVars(ctx, m.runner),
-		loader.LoadMiseVersion(ctx, m.runner),
-		loader.LoadMiseConfigFiles(ctx, m.runner),
+		loader.LoadMiseTasks(ctx, m.mise, m.showHidden),
+		loader.LoadMiseTools(ctx, m.mise),
+		loader.LoadMiseEnvVars(ctx, m.mise),
+		loader.LoadMiseVersion(ctx, m.mise),
+		loader.LoadMiseConfigFiles(ctx, m.mise),
 	)
 }
 
@@ -222,13 +478,23 @@ func (m model) Init() tea.Cmd {
 //
 //nolint:funlen // Function has 52 statements, slightly over 50 limit
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Task runs keep going in the background while pickers and inputs are open
+	if next, cmd, ok := m.handleRunMsg(msg); ok {
+		return next, cmd
+	}
+
 	// When picker is open, route messages to the picker (lists need all msg types for filtering)
 	if m.pickerState != pickerClosed {
 		return m.handlePickerUpdate(msg)
 	}
 
-	// When filter is active, route messages to filter input handler
-	if m.filterActive {
+	// When the new task wizard is open, route key presses to it
+	if keyMsg, ok := msg.(tea.KeyPressMsg); ok && m.wizardStep != wizardClosed {
+		return m.handleWizardKeys(keyMsg)
+	}
+
+	// While the focused table's filter is edited, route messages to the filter input handler
+	if m.filters[m.focus].editing {
 		return m.handleFilterInput(msg)
 	}
 
@@ -237,15 +503,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleArgInput(msg)
 	}
 
-	switch msg := msg.(type) {
-	case spinner.TickMsg:
-		if m.taskRunning {
-			var cmd tea.Cmd
-			m.taskSpinner, cmd = m.taskSpinner.Update(msg)
-			return m, cmd
-		}
-		return m, nil
+	if m.batchPromptActive {
+		return m.handleBatchPrompt(msg)
+	}
+
+	if m.confirm.active() {
+		return m.handleConfirmInput(msg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyPressMsg); ok && m.toolDetail.open {
+		return m.handleToolDetailKeys(keyMsg)
+	}
 
+	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
 		m.logger.Debug("handling key pess", "key", msg)
 		// Handle keys differently based on whether we're showing output
@@ -259,11 +529,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m = newModel
 		// Fall through to let tables handle navigation keys
 
-	case taskOutputMsg:
-		return m.handleTaskOutput(msg), nil
+	case taskInputSentMsg:
+		return m.handleTaskInputSent(msg), nil
 
-	case taskDoneMsg:
-		return m.handleTaskDone(msg), nil
+	case outputSavedMsg:
+		return m.handleOutputSaved(msg), nil
+
+	case outputCopiedMsg:
+		return m.handleOutputCopied(msg)
 
 	case loader.TasksLoadedMsg:
 		return m.handleTasksLoaded(msg), nil
@@ -275,16 +548,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleEnvVarsLoaded(msg), nil
 
 	case loader.MiseVersionMsg:
-		return m.handleMiseVersion(msg), nil
+		m = m.handleMiseVersion(msg)
+		return m, m.pruneToolCache()
 
 	case loader.ConfigFilesLoadedMsg:
 		return m.handleConfigFilesLoaded(msg), nil
 
 	case loader.RegistryLoadedMsg:
-		return m.handleRegistryLoaded(msg), nil
+		return m.handleRegistryLoaded(msg)
 
 	case loader.VersionsLoadedMsg:
-		return m.handleVersionsLoaded(msg), nil
+		return m.handleVersionsLoaded(msg)
 
 	case loader.ToolInstalledMsg:
 		return m.handleToolInstalled(msg)
@@ -292,6 +566,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case loader.ToolRemovedMsg:
 		return m.handleToolRemoved(msg)
 
+	case loader.ToolDetailLoadedMsg:
+		return m.handleToolDetailLoaded(msg), nil
+
 	case watcher.FileChangedMsg:
 		return m.handleFileChanged(msg)
 
@@ -301,6 +578,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case interactiveTaskClosedMsg:
 		return m.handleInteractiveTaskClosed(msg), nil
 
+	case taskCreatedMsg:
+		return m.handleTaskCreated(msg)
+
 	case tea.WindowSizeMsg:
 		return m.handleWindowSize(msg), nil
 	}
@@ -312,8 +592,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) updateFocusedComponent(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	// Update wizard inputs (cursor blink etc.)
+	switch m.wizardStep {
+	case wizardName, wizardDescription, wizardDepends:
+		m.wizardInput, cmd = m.wizardInput.Update(msg)
+		return m, cmd
+	case wizardRun:
+		m.wizardScript, cmd = m.wizardScript.Update(msg)
+		return m, cmd
+	case wizardTarget:
+		m.wizardTargets, cmd = m.wizardTargets.Update(msg)
+		return m, cmd
+	case wizardClosed, wizardWriting:
+	}
+
 	// Update viewport when showing output
 	if m.showOutput {
+		if m.saveInputActive {
+			m.saveInput, cmd = m.saveInput.Update(msg)
+			return m, cmd
+		}
+		if m.stdinInputActive {
+			m.stdinInput, cmd = m.stdinInput.Update(msg)
+			return m, cmd
+		}
+		if m.search.editing {
+			m.search.input, cmd = m.search.input.Update(msg)
+			return m, cmd
+		}
 		m.viewport, cmd = m.viewport.Update(msg)
 		return m, cmd
 	}
@@ -343,6 +649,9 @@ func (m model) renderHeader() string {
 	if m.miseVersion != "" {
 		versionLine = m.styles.help.Render("mise v" + m.miseVersion)
 	}
+	if jobs := m.renderJobsStatus(); jobs != "" {
+		versionLine = lipgloss.JoinHorizontal(lipgloss.Top, versionLine, "  ", jobs)
+	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, tagline, versionLine)
 }
@@ -355,11 +664,28 @@ func (m model) View() tea.View {
 		return m.renderPickerView()
 	}
 
+	// Show new task wizard if open
+	if m.wizardStep != wizardClosed {
+		return m.renderWizardView()
+	}
+
 	// Show argument input view if active
 	if m.argInputActive {
 		return m.renderArgInputView()
 	}
 
+	if m.batchPromptActive {
+		return m.renderBatchPromptView()
+	}
+
+	if m.confirm.active() {
+		return m.renderConfirmView()
+	}
+
+	if m.toolDetail.open {
+		return m.renderToolDetailView()
+	}
+
 	// Show output view if running or viewing task output
 	if m.showOutput {
 		return m.renderOutputView()
@@ -376,18 +702,20 @@ func (m model) View() tea.View {
 	// Build sections using shared renderTitle helper
 	header := m.renderHeader()
 	tasksTitle := m.styles.renderTitle("Tasks", m.focus == focusTasks)
+	if marked := len(m.markedTaskNames()); marked > 0 {
+		tasksTitle += m.styles.help.Render(fmt.Sprintf(" %d marked", marked))
+	}
 	toolsTitle := m.styles.renderTitle("Tools", m.focus == focusTools)
 	envVarsTitle := m.styles.renderTitle("Environment Variables", m.focus == focusEnvVars)
 
-	// Build tasks section with optional filter input
-	tasksSection := tasksTitle
-	if filterView := m.renderFilterInput(); filterView != "" {
-		tasksSection = lipgloss.JoinVertical(lipgloss.Left, tasksSection, filterView)
-	}
+	// Build sections with optional filter input
+	tasksSection := m.withFilterInput(tasksTitle, focusTasks)
+	toolsSection := m.withFilterInput(toolsTitle, focusTools)
+	envVarsSection := m.withFilterInput(envVarsTitle, focusEnvVars)
 
 	// Get contextual help based on focus or filter state
 	var helpView string
-	if m.filterActive {
+	if m.filters[m.focus].editing {
 		helpView = m.filterHelp.View(m.filterKeys)
 	} else {
 		switch m.focus {
@@ -408,14 +736,17 @@ func (m model) View() tea.View {
 		tasksSection,
 		m.tasksTable.View(),
 		"",
-		toolsTitle,
+		toolsSection,
 		m.toolsTable.View(),
 		"",
-		envVarsTitle,
+		envVarsSection,
 		m.envVarsTable.View(),
 		"",
 		helpView,
 	)
+	if m.notice != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, m.notice)
+	}
 
 	v := tea.NewView(content)
 	v.AltScreen = true
@@ -435,6 +766,13 @@ func (m model) renderPickerView() tea.View {
 			lipgloss.Left,
 			m.toolList.View(),
 		)
+	case pickerSelectBackend:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.backendList.View(),
+		)
+	case pickerEnterCustomTool:
+		content = m.renderCustomToolView()
 	case pickerLoadingVersions:
 		content = fmt.Sprintf("Loading versions for %s...", m.selectedTool)
 	case pickerSelectVersion:
@@ -458,35 +796,55 @@ func (m model) renderPickerView() tea.View {
 
 // renderOutputView renders the task output viewport.
 func (m model) renderOutputView() tea.View {
+	label := "Task"
+	if m.dryRun {
+		label = "Preview"
+	}
 	var title string
 	if m.totalOutputLines > maxOutputLines {
-		title = m.styles.title.Render(fmt.Sprintf("Task: %s (showing last %d of %d lines)",
-			m.runningTask, maxOutputLines, m.totalOutputLines))
+		title = m.styles.title.Render(fmt.Sprintf("%s: %s (showing last %d of %d lines)",
+			label, m.runningTask, maxOutputLines, m.totalOutputLines))
 	} else {
-		title = m.styles.title.Render(fmt.Sprintf("Task: %s", m.runningTask))
+		title = m.styles.title.Render(fmt.Sprintf("%s: %s", label, m.runningTask))
 	}
 
-	var status string
-	switch {
-	case m.taskRunning:
-		status = m.styles.dimTitle.Render(m.taskSpinner.View() + " Running...")
-	case m.taskErr != nil:
-		status = m.styles.err.Render(fmt.Sprintf("✗ Failed: %v", m.taskErr))
-	default:
-		status = m.styles.success.Render("✓ Completed")
+	if len(m.runningEnv) > 0 {
+		title = lipgloss.JoinHorizontal(lipgloss.Top, title, " ", m.styles.help.Render(maskedOverrides(m.runningEnv)))
+	}
+	header := lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", m.renderRunStatus())
+	if watch := m.renderWatchStatus(); watch != "" {
+		header = lipgloss.JoinHorizontal(lipgloss.Top, header, "  ", watch)
+	}
+	if batch := m.renderBatchStatus(); batch != "" {
+		header = lipgloss.JoinHorizontal(lipgloss.Top, header, "  ", batch)
+	}
+	if m.streams != showAllStreams {
+		header = lipgloss.JoinHorizontal(lipgloss.Top, header, "  ",
+			m.styles.help.Render(fmt.Sprintf("[%s only]", m.streams)))
 	}
-
-	header := lipgloss.JoinHorizontal(lipgloss.Top, title, "  ", status)
 
 	// Update output keys based on running state and render help
 	m.outputKeys = newOutputKeyMap(m.taskRunning)
+	// Only runs started with input have a stdin to write to
+	m.outputKeys.Input.SetEnabled(m.stdin != nil)
+	if m.watch.active() {
+		m.outputKeys.Watch.SetHelp("W", "stop watching")
+	}
 	helpView := m.outputHelp.View(m.outputKeys)
+	switch {
+	case m.saveInputActive:
+		helpView = m.outputHelp.View(newSaveKeyMap())
+	case m.stdinInputActive:
+		helpView = m.outputHelp.View(newStdinKeyMap())
+	case m.search.editing:
+		helpView = m.outputHelp.View(newSearchKeyMap())
+	}
 
 	// Build the view
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		header,
-		"",
+		m.renderOutputBar(),
 		m.viewport.View(),
 		"",
 		helpView,
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// notifyTimeout bounds how long a desktop notification command may take.
+const notifyTimeout = 5 * time.Second
+
+// ErrUnknownNotifier is returned for a notifier name that isn't supported.
+var ErrUnknownNotifier = errors.New("unknown notifier")
+
+// notifier tells the user that a background job finished.
+type notifier interface {
+	Notify(title, body string) tea.Cmd
+}
+
+// notificationSentMsg is sent when a notification command has finished.
+type notificationSentMsg struct {
+	err error
+}
+
+// notifierNames lists the notifiers accepted by newNotifier.
+const notifierNames = "bell, terminal, desktop or none"
+
+// newNotifier returns the notifier called name. "none" returns a nil notifier.
+func newNotifier(name string) (notifier, error) {
+	switch name {
+	case "bell":
+		return bellNotifier{}, nil
+	case "terminal":
+		return terminalNotifier{}, nil
+	case "desktop":
+		return desktopNotifier{goos: runtime.GOOS}, nil
+	case "none":
+		return nil, nil //nolint:nilnil // no notifier is a valid choice
+	}
+	return nil, fmt.Errorf("%w %q, use %s", ErrUnknownNotifier, name, notifierNames)
+}
+
+// bellNotifier rings the terminal bell.
+type bellNotifier struct{}
+
+// Notify rings the bell.
+func (bellNotifier) Notify(_, _ string) tea.Cmd {
+	return tea.Raw(string(rune(ansi.BEL)))
+}
+
+// terminalNotifier asks the terminal to show a notification with OSC 9,
+// which terminals like iTerm2, WezTerm, kitty and Windows Terminal support.
+type terminalNotifier struct{}
+
+// Notify writes the notification escape sequence.
+func (terminalNotifier) Notify(title, body string) tea.Cmd {
+	return tea.Raw(ansi.Notify(title + ": " + body))
+}
+
+// desktopNotifier shows a notification with the desktop's notification
+// command: notify-send on Linux and the BSDs, osascript on macOS.
+type desktopNotifier struct {
+	goos string
+}
+
+// command returns the notification command for the notifier's OS.
+func (n desktopNotifier) command(title, body string) []string {
+	if n.goos == "darwin" {
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+		return []string{"osascript", "-e", script}
+	}
+	return []string{"notify-send", "--app-name=prep", title, body}
+}
+
+// Notify runs the notification command.
+func (n desktopNotifier) Notify(title, body string) tea.Cmd {
+	args := n.command(title, body)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+		//nolint:gosec // the command is fixed, title and body are passed as arguments
+		if out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput(); err != nil {
+			return notificationSentMsg{err: fmt.Errorf("%s: %w: %s", args[0], err, out)}
+		}
+		return notificationSentMsg{}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNewNotifier(t *testing.T) {
+	for _, name := range []string{"bell", "terminal", "desktop"} {
+		if n, err := newNotifier(name); err != nil || n == nil {
+			t.Errorf("newNotifier(%q) = %v, %v", name, n, err)
+		}
+	}
+	if n, err := newNotifier("none"); err != nil || n != nil {
+		t.Errorf("newNotifier(none) = %v, %v, want no notifier", n, err)
+	}
+	if _, err := newNotifier("pager"); !errors.Is(err, ErrUnknownNotifier) {
+		t.Errorf("newNotifier(pager) error = %v, want ErrUnknownNotifier", err)
+	}
+}
+
+func TestDesktopNotifierCommand(t *testing.T) {
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{goos: "linux", want: []string{"notify-send", "--app-name=prep", "prep: build", `Failed: "exit"`}},
+		{goos: "darwin", want: []string{
+			"osascript", "-e", `display notification "Failed: \"exit\"" with title "prep: build"`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			got := desktopNotifier{goos: tt.goos}.command("prep: build", `Failed: "exit"`)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
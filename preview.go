@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// taskDirs are the directories below a project's root that mise reads file tasks from.
+var taskDirs = []string{".config/mise/tasks", ".mise/tasks", "mise/tasks", ".mise-tasks", "mise-tasks"}
+
+// configDirs are the directories below a project's root that hold its
+// config.toml, or its mise.toml for .config.
+var configDirs = []string{".config/mise", ".mise", "mise", ".config"}
+
+// configRoot returns the root of the project task is defined in: the
+// directory of its config file, or the one holding mise's config directory.
+func configRoot(task loader.Task, cwd string) string {
+	if task.Source == "" {
+		return cwd
+	}
+	dir := filepath.ToSlash(filepath.Dir(task.Source))
+	for _, taskDir := range taskDirs {
+		if i := strings.LastIndex(dir+"/", "/"+taskDir+"/"); i >= 0 {
+			return filepath.FromSlash(dir[:i])
+		}
+	}
+	// Only config files named config.toml live in mise's own directories,
+	// mise.toml is read from .config too
+	base := filepath.Base(task.Source)
+	for _, configDir := range configDirs {
+		inConfigDir := strings.HasPrefix(base, "config.") || configDir == ".config"
+		if root, ok := strings.CutSuffix(dir, "/"+configDir); ok && inConfigDir {
+			return filepath.FromSlash(root)
+		}
+	}
+	return filepath.FromSlash(dir)
+}
+
+// taskDir returns the directory task runs in, which its sources globs are
+// relative to too. mise runs tasks in the project root unless they set a
+// dir, which is relative to the root.
+func taskDir(task loader.Task, cwd string) string {
+	root := configRoot(task, cwd)
+	switch {
+	case task.Dir == "":
+		return root
+	case filepath.IsAbs(task.Dir):
+		return task.Dir
+	}
+	return filepath.Join(root, task.Dir)
+}
+
+// dryRunCommand returns the mise arguments that print what running task with
+// args would execute: the tasks in the order they would run, dependencies
+// first, with their rendered scripts.
+func dryRunCommand(task string, args []string) []string {
+	cmdArgs := []string{"run", "--dry-run", task}
+	if len(args) > 0 {
+		cmdArgs = append(cmdArgs, "--")
+		cmdArgs = append(cmdArgs, args...)
+	}
+	return cmdArgs
+}
+
+// previewSelectedTask shows what running the selected task would execute.
+func (m model) previewSelectedTask() (model, tea.Cmd, bool) {
+	task, ok := m.selectedTask()
+	if !ok {
+		return m, nil, true
+	}
+	m, cmd := m.previewTask(task, nil)
+	return m, cmd, true
+}
+
+// previewTask shows in the output view what running task with env and args
+// would execute, without executing anything. Where it runs and the
+// environment it gets come first, then what mise prints for a dry run.
+func (m model) previewTask(task loader.Task, env []string, args ...string) (model, tea.Cmd) {
+	m = m.resetOutputView()
+	m.dryRun = true
+	m, cmd := m.launchRun(task.Name, dryRunCommand(task.Name, args), env, args, false)
+	for _, line := range m.previewHeader(task, env) {
+		m = m.handleTaskOutput(taskOutputMsg{run: m.runID, line: line, at: time.Now()})
+	}
+	return m, cmd
+}
+
+// previewHeader describes where task would run and the environment it would get.
+func (m model) previewHeader(task loader.Task, env []string) []string {
+	lines := []string{
+		"Working directory: " + taskDir(task, m.cwd),
+		fmt.Sprintf("Environment: %d variables from mise", len(m.envVars)),
+	}
+	if len(task.Env) > 0 {
+		lines = append(lines, "Task environment:")
+		for _, name := range slices.Sorted(maps.Keys(task.Env)) {
+			lines = append(lines, fmt.Sprintf("  %s=%s", name, task.Env[name]))
+		}
+	}
+	if len(env) > 0 {
+		lines = append(lines, "Environment overrides: "+maskedOverrides(env))
+	}
+	return append(lines, "Tasks in the order they would run:")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestTaskDir(t *testing.T) {
+	tests := []struct {
+		name string
+		task loader.Task
+		want string
+	}{
+		{name: "no source", task: loader.Task{Name: "build"}, want: "/work"},
+		{name: "mise.toml", task: loader.Task{Source: "/src/app/mise.toml"}, want: "/src/app"},
+		{name: "project named mise", task: loader.Task{Source: "/src/mise/mise.toml"}, want: "/src/mise"},
+		{name: "config dir", task: loader.Task{Source: "/src/app/.config/mise/config.toml"}, want: "/src/app"},
+		{name: "mise.toml in .config", task: loader.Task{Source: "/src/app/.config/mise.toml"}, want: "/src/app"},
+		{name: "file task", task: loader.Task{Source: "/src/app/mise-tasks/lint"}, want: "/src/app"},
+		{name: "nested file task", task: loader.Task{Source: "/src/app/.mise/tasks/db/migrate"}, want: "/src/app"},
+		{name: "relative dir", task: loader.Task{Source: "/src/app/mise.toml", Dir: "web"}, want: "/src/app/web"},
+		{name: "absolute dir", task: loader.Task{Source: "/src/app/mise.toml", Dir: "/tmp"}, want: "/tmp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskDir(tt.task, "/work"); got != tt.want {
+				t.Errorf("taskDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunCommand(t *testing.T) {
+	want := []string{"run", "--dry-run", "release", "--", "--tag", "v1"}
+	if got := dryRunCommand("release", []string{"--tag", "v1"}); !slices.Equal(got, want) {
+		t.Errorf("dryRunCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewTask(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"release"}))
+	m.cwd = "/src/app"
+
+	task := loader.Task{Name: "release", Source: "/src/app/mise.toml", Env: loader.TaskEnv{"CHANNEL": "beta"}}
+	m, _ = m.previewTask(task, []string{"TOKEN=s3cret"})
+	if !m.dryRun || !m.showOutput {
+		t.Fatalf("dryRun = %v, showOutput = %v, want a preview in the output view", m.dryRun, m.showOutput)
+	}
+	output := strings.Join(outputTexts(m.output), "\n")
+	for _, want := range []string{"Working directory: /src/app", "  CHANNEL=beta", "Environment overrides: TOKEN=●"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("preview output %q is missing %q", output, want)
+		}
+	}
+	if title := ansi.Strip(fmt.Sprint(m.renderOutputView().Content)); strings.Contains(output+title, "s3cret") {
+		t.Errorf("the preview shows the override value in %q", output+title)
+	}
+
+	m, _, _ = m.handleRunMsg(taskDoneMsg{run: m.runID})
+	if got := ansi.Strip(m.renderRunStatus()); !strings.Contains(got, "nothing was executed") {
+		t.Errorf("renderRunStatus() = %q, want it to say nothing was executed", got)
+	}
+
+	// A new run is no preview
+	m, _ = m.startTask("release", nil)
+	if m.dryRun {
+		t.Error("dryRun is still set for a task run")
+	}
+}
+
+func TestPreviewFromArgInput(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"release"}))
+	m.argInputActive = true
+	m.argInputTask = "release"
+	m.argInput.SetValue("--tag v1")
+	m.envInput.SetValue("DRY=1")
+
+	next, _ := m.handleArgInput(tea.KeyPressMsg{Code: 'p', Mod: tea.ModCtrl})
+	m = next.(model)
+	if m.argInputActive || !m.dryRun || m.runningTask != "release" {
+		t.Fatalf("argInputActive = %v, dryRun = %v, want release previewed", m.argInputActive, m.dryRun)
+	}
+	if !slices.Equal(m.runningArgs, []string{"--tag", "v1"}) || !slices.Equal(m.runningEnv, []string{"DRY=1"}) {
+		t.Errorf("previewed with args %q env %q, want the typed ones", m.runningArgs, m.runningEnv)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// defaultStopGrace is how long a cancelled task gets to exit before it is killed.
+const defaultStopGrace = 5 * time.Second
+
+// processGroupPollInterval is how often a stopping process group is checked for exit.
+const processGroupPollInterval = 50 * time.Millisecond
+
+// stopOnCancel stops the process group of the started cmd once ctx is cancelled.
+// The group is interrupted first, terminated halfway through the grace period
+// and killed once it is over. The returned function ends the watch and must be
+// called once cmd has been waited for.
+func stopOnCancel(ctx context.Context, cmd *exec.Cmd, grace time.Duration) func() {
+	done := make(chan struct{})
+	pgid := cmd.Process.Pid
+
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+		}
+
+		_ = signalProcessGroup(pgid, syscall.SIGINT)
+		terminate := time.NewTimer(grace / 2) //nolint:mnd // halfway through the grace period
+		defer terminate.Stop()
+		kill := time.NewTimer(grace)
+		defer kill.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-terminate.C:
+				_ = signalProcessGroup(pgid, syscall.SIGTERM)
+			case <-kill.C:
+				_ = signalProcessGroup(pgid, syscall.SIGKILL)
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// waitForProcessGroup blocks until every process in the group is gone or the
+// timeout passes. Children can outlive mise, so a cancelled run is only over
+// once its whole group has exited.
+func waitForProcessGroup(pgid int, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for processGroupAlive(pgid) && time.Now().Before(deadline) {
+		time.Sleep(processGroupPollInterval)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup is a no-op, process groups are only used on unix.
+func setProcessGroup(_ *exec.Cmd) {}
+
+// signalProcessGroup kills the process. Other platforms can't deliver
+// interrupts to another process, so every signal kills it.
+func signalProcessGroup(pid int, _ syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+// processGroupAlive reports false, the process is gone once it has been waited for.
+func processGroupAlive(_ int) bool {
+	return false
+}
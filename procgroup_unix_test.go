@@ -0,0 +1,99 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestStopOnCancel(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		grace   time.Duration
+		maxWait time.Duration
+	}{
+		{
+			name:    "interrupt stops the group",
+			script:  "sleep 30; true",
+			grace:   10 * time.Second,
+			maxWait: 2 * time.Second,
+		},
+		{
+			name:    "kill after grace period when interrupts are ignored",
+			script:  "trap '' INT TERM; sleep 30 & wait",
+			grace:   200 * time.Millisecond,
+			maxWait: 3 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			setProcessGroup(cmd)
+			if err := cmd.Start(); err != nil {
+				t.Fatalf("starting command: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stop := stopOnCancel(ctx, cmd, tt.grace)
+			defer stop()
+
+			// Give the shell time to set up its traps and start the child
+			time.Sleep(100 * time.Millisecond)
+			start := time.Now()
+			cancel()
+
+			_ = cmd.Wait()
+			waitForProcessGroup(cmd.Process.Pid, tt.maxWait)
+			if processGroupAlive(cmd.Process.Pid) {
+				t.Fatal("process group still alive after cancellation")
+			}
+			if elapsed := time.Since(start); elapsed > tt.maxWait {
+				t.Errorf("stopping took %v, want at most %v", elapsed, tt.maxWait)
+			}
+		})
+	}
+}
+
+// recordingSender collects the messages sent to it.
+type recordingSender struct {
+	mu   sync.Mutex
+	msgs []tea.Msg
+}
+
+func (s *recordingSender) Send(msg tea.Msg) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, msg)
+}
+
+func TestRunTask_BackgroundChild(t *testing.T) {
+	sender := &recordingSender{}
+	cmd := runTask(context.Background(), 1, []string{"sh", "-c", "echo started; sleep 30 & exit 0"}, nil,
+		time.Second, nil, sender)
+
+	done := make(chan tea.Msg)
+	go func() { done <- cmd() }()
+	var msg tea.Msg
+	select {
+	case msg = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the run didn't finish while a background child held its output open")
+	}
+
+	if result, ok := msg.(taskDoneMsg); !ok || result.err != nil {
+		t.Errorf("runTask() = %#v, want a successful taskDoneMsg", msg)
+	}
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if len(sender.msgs) == 0 || sender.msgs[0].(taskOutputMsg).line != "started" {
+		t.Errorf("sent %v, want the output of the task", sender.msgs)
+	}
+}
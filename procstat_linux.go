@@ -0,0 +1,26 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// rusageMaxRSSUnit converts Rusage.Maxrss to bytes, Linux reports it in kilobytes.
+const rusageMaxRSSUnit = 1024
+
+// processStats returns the signal that terminated the process, if any, and its
+// peak resident set size in bytes. The peak includes descendants the process
+// waited for, which covers the task scripts mise runs.
+func processStats(ps *os.ProcessState) (string, int64) {
+	var signal string
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		signal = ws.Signal().String()
+	}
+	var peakRSS int64
+	if ru, ok := ps.SysUsage().(*syscall.Rusage); ok {
+		peakRSS = ru.Maxrss * rusageMaxRSSUnit
+	}
+	return signal, peakRSS
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package main
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestNewRunStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       string
+		wantExitCode int
+		wantSignal   string
+	}{
+		{name: "exit code", script: "exit 3", wantExitCode: 3},
+		{name: "signal", script: "kill -TERM $$", wantExitCode: -1, wantSignal: "terminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			_ = cmd.Run()
+
+			stats := newRunStats(cmd.ProcessState, time.Second)
+			if stats.exitCode != tt.wantExitCode {
+				t.Errorf("exitCode = %d, want %d", stats.exitCode, tt.wantExitCode)
+			}
+			if stats.signal != tt.wantSignal {
+				t.Errorf("signal = %q, want %q", stats.signal, tt.wantSignal)
+			}
+			if stats.peakRSS <= 0 {
+				t.Errorf("peakRSS = %d, want > 0", stats.peakRSS)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import "os"
+
+// processStats returns the signal that terminated the process and its peak
+// resident set size. Neither is reported outside Linux.
+func processStats(_ *os.ProcessState) (string, int64) {
+	return "", 0
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"charm.land/bubbles/v2/textinput"
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// errorPattern recognizes lines that commonly mark a failure in task output:
+// go test's FAIL, Go panics and "error:" in any case.
+const errorPattern = `\bFAIL\b|panic:|(?i:\berror:)`
+
+// outputSearch is the state of a search in the output view.
+type outputSearch struct {
+	input   textinput.Model
+	editing bool           // whether the input has focus and receives key presses
+	re      *regexp.Regexp // compiled query, nil when no search is applied
+	matches []outputMatch  // matches in the output buffer, in order
+	current int            // index into matches of the focused match, -1 if none
+	from    int            // output line the search started from
+}
+
+// outputMatch is a match in the output buffer. start and end are byte offsets
+// into the line with ANSI escape codes stripped.
+type outputMatch struct {
+	line  int
+	start int
+	end   int
+}
+
+// newOutputSearch creates an outputSearch with an empty query.
+func newOutputSearch() outputSearch {
+	input := textinput.New()
+	input.Prompt = "/"
+	input.Placeholder = "search (regex)"
+	input.CharLimit = filterCharLimit
+	input.SetWidth(defaultInputWidth)
+	return outputSearch{input: input, current: -1}
+}
+
+// applied reports whether a search is highlighting matches.
+func (s outputSearch) applied() bool {
+	return s.re != nil
+}
+
+// compileSearch compiles a search query. The query is a regular expression,
+// and is matched literally while it is not a valid one so incremental search
+// keeps working halfway through typing "foo(". Like vim's smartcase, the
+// search ignores case unless the query has an upper case letter.
+func compileSearch(query string) *regexp.Regexp {
+	if query == "" {
+		return nil
+	}
+	flags := "(?i)"
+	if strings.IndexFunc(query, unicode.IsUpper) >= 0 {
+		flags = ""
+	}
+	if re, err := regexp.Compile(flags + query); err == nil {
+		return re
+	}
+	return regexp.MustCompile(flags + regexp.QuoteMeta(query))
+}
+
+// findOutputMatches returns every match of re in lines, ignoring ANSI escape
+// codes. Empty matches are skipped since they cannot be highlighted.
+func findOutputMatches(lines []string, re *regexp.Regexp) []outputMatch {
+	if re == nil {
+		return nil
+	}
+	var matches []outputMatch
+	for i, line := range lines {
+		matches = appendLineMatches(matches, line, i, re)
+	}
+	return matches
+}
+
+// appendLineMatches appends the matches of re in line, the output line at index.
+func appendLineMatches(matches []outputMatch, line string, index int, re *regexp.Regexp) []outputMatch {
+	for _, loc := range re.FindAllStringIndex(ansi.Strip(line), -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		matches = append(matches, outputMatch{line: index, start: loc[0], end: loc[1]})
+	}
+	return matches
+}
+
+// rolled returns the search after its first lines rolled out of the output
+// buffer. Their matches are dropped and the rest move up, keeping the focus
+// on the same match, or on the first one left if the focused match rolled off.
+func (s outputSearch) rolled(lines int) outputSearch {
+	if lines <= 0 {
+		return s
+	}
+	dropped := 0
+	for dropped < len(s.matches) && s.matches[dropped].line < lines {
+		dropped++
+	}
+	matches := make([]outputMatch, 0, len(s.matches)-dropped)
+	for _, match := range s.matches[dropped:] {
+		match.line -= lines
+		matches = append(matches, match)
+	}
+	s.matches = matches
+	if s.current >= 0 {
+		s.current = max(s.current-dropped, 0)
+		if len(s.matches) == 0 {
+			s.current = -1
+		}
+	}
+	return s
+}
+
+// nextMatchFrom returns the index of the first match on or after line,
+// wrapping around to the first match. It returns -1 when there are no matches.
+func nextMatchFrom(matches []outputMatch, line int) int {
+	if len(matches) == 0 {
+		return -1
+	}
+	for i, match := range matches {
+		if match.line >= line {
+			return i
+		}
+	}
+	return 0
+}
+
+// highlightOutputLines returns lines with search matches highlighted and the
+// focused match in current. Lines with matches lose their own colors because
+// the matches are found in the stripped text.
+func highlightOutputLines(lines []string, matches []outputMatch, focused int, hl, current lipgloss.Style) []string {
+	if len(matches) == 0 {
+		return lines
+	}
+	highlighted := make([]string, len(lines))
+	copy(highlighted, lines)
+
+	for i := 0; i < len(matches); {
+		lineIdx := matches[i].line
+		plain := ansi.Strip(lines[lineIdx])
+
+		var b strings.Builder
+		pos := 0
+		for ; i < len(matches) && matches[i].line == lineIdx; i++ {
+			match := matches[i]
+			style := hl
+			if i == focused {
+				style = current
+			}
+			b.WriteString(plain[pos:match.start])
+			b.WriteString(style.Render(plain[match.start:match.end]))
+			pos = match.end
+		}
+		b.WriteString(plain[pos:])
+		highlighted[lineIdx] = b.String()
+	}
+	return highlighted
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/bubbles/v2/viewport"
+	"charm.land/lipgloss/v2"
+)
+
+func TestCompileSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		line    string
+		wantHit bool
+	}{
+		{name: "lower case query ignores case", query: "fail", line: "--- FAIL: TestX", wantHit: true},
+		{name: "upper case query is case sensitive", query: "Fail", line: "--- FAIL: TestX", wantHit: false},
+		{name: "regular expression", query: `ok\s+\d`, line: "ok   42 tests", wantHit: true},
+		{name: "invalid regex matches literally", query: "foo(", line: "call foo(bar)", wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re := compileSearch(tt.query)
+			if got := re.MatchString(tt.line); got != tt.wantHit {
+				t.Errorf("compileSearch(%q).MatchString(%q) = %v, want %v", tt.query, tt.line, got, tt.wantHit)
+			}
+		})
+	}
+
+	if re := compileSearch(""); re != nil {
+		t.Errorf("compileSearch(\"\") = %v, want nil", re)
+	}
+}
+
+func TestFindOutputMatches(t *testing.T) {
+	lines := []string{
+		"\x1b[31merror\x1b[0m: boom",
+		"nothing here",
+		"error and error",
+	}
+	got := findOutputMatches(lines, compileSearch("error"))
+	want := []outputMatch{
+		{line: 0, start: 0, end: 5},
+		{line: 2, start: 0, end: 5},
+		{line: 2, start: 10, end: 15},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findOutputMatches() = %v, want %v", got, want)
+	}
+
+	// Empty matches can't be highlighted
+	if got := findOutputMatches(lines, compileSearch("x*")); len(got) != 0 {
+		t.Errorf("findOutputMatches(x*) = %v, want none", got)
+	}
+}
+
+func TestErrorPattern(t *testing.T) {
+	re := compileSearch(errorPattern)
+	for _, line := range []string{
+		"--- FAIL: TestParse (0.00s)",
+		"FAIL\tgithub.com/x/y\t0.012s",
+		"panic: runtime error: index out of range",
+		"Error: exit status 1",
+		"main.go:12:3: error: undefined",
+	} {
+		if !re.MatchString(line) {
+			t.Errorf("errorPattern does not match %q", line)
+		}
+	}
+	for _, line := range []string{"ok  github.com/x/y", "FAILURES=0", "no errors found"} {
+		if re.MatchString(line) {
+			t.Errorf("errorPattern matches %q", line)
+		}
+	}
+}
+
+func TestNextMatchFrom(t *testing.T) {
+	matches := []outputMatch{{line: 2}, {line: 5}, {line: 9}}
+	tests := []struct {
+		line int
+		want int
+	}{
+		{line: 0, want: 0},
+		{line: 5, want: 1},
+		{line: 6, want: 2},
+		{line: 10, want: 0}, // wraps around
+	}
+	for _, tt := range tests {
+		if got := nextMatchFrom(matches, tt.line); got != tt.want {
+			t.Errorf("nextMatchFrom(%d) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+	if got := nextMatchFrom(nil, 0); got != -1 {
+		t.Errorf("nextMatchFrom(nil) = %d, want -1", got)
+	}
+}
+
+func TestHighlightOutputLines(t *testing.T) {
+	hl := lipgloss.NewStyle().Bold(true)
+	lines := []string{"a foo b foo", "plain"}
+	matches := []outputMatch{{line: 0, start: 2, end: 5}, {line: 0, start: 8, end: 11}}
+
+	got := highlightOutputLines(lines, matches, 1, hl, hl)
+	if got[0] != "a "+hl.Render("foo")+" b "+hl.Render("foo") {
+		t.Errorf("highlighted line = %q", got[0])
+	}
+	if got[1] != "plain" {
+		t.Errorf("line without matches = %q, want %q", got[1], "plain")
+	}
+	// The output buffer itself is left untouched
+	if lines[0] != "a foo b foo" {
+		t.Errorf("input line was modified: %q", lines[0])
+	}
+}
+
+// stdoutLines returns output lines written to stdout, rendered as is.
+func stdoutLines(texts ...string) []outputLine {
+	lines := make([]outputLine, len(texts))
+	for i, text := range texts {
+		lines[i] = outputLine{text: text, display: text}
+	}
+	return lines
+}
+
+func TestOutputSearch_Navigation(t *testing.T) {
+	m := model{
+		styles:   newStyles(),
+		search:   newOutputSearch(),
+		viewport: viewport.New(viewport.WithWidth(80), viewport.WithHeight(5)),
+		taskRun: taskRun{output: stdoutLines(
+			"=== RUN TestA",
+			"--- FAIL: TestA",
+			"=== RUN TestB",
+			"panic: nil map",
+			"ok",
+		)},
+	}
+
+	m = m.jumpToFirstError()
+	if len(m.search.matches) != 2 || m.search.current != 0 {
+		t.Fatalf("jumpToFirstError() matches = %v, current = %d, want 2 matches, current 0",
+			m.search.matches, m.search.current)
+	}
+	if line := m.search.matches[m.search.current].line; line != 1 {
+		t.Errorf("first error on line %d, want 1", line)
+	}
+
+	m = m.moveMatch(1)
+	if line := m.search.matches[m.search.current].line; line != 3 {
+		t.Errorf("next match on line %d, want 3", line)
+	}
+	m = m.moveMatch(1)
+	if m.search.current != 0 {
+		t.Errorf("next match after the last = %d, want 0", m.search.current)
+	}
+	m = m.moveMatch(-1)
+	if m.search.current != 1 {
+		t.Errorf("previous match before the first = %d, want 1", m.search.current)
+	}
+
+	m = m.clearOutputSearch()
+	if m.search.applied() {
+		t.Error("search still applied after clearOutputSearch")
+	}
+}
+
+func TestOutputSearch_Rolled(t *testing.T) {
+	s := outputSearch{
+		matches: []outputMatch{{line: 0}, {line: 1}, {line: 3}, {line: 4}},
+		current: 2,
+	}
+
+	s = s.rolled(2)
+	want := []outputMatch{{line: 1}, {line: 2}}
+	if !slices.Equal(s.matches, want) || s.current != 0 {
+		t.Errorf("rolled(2) = %v current %d, want %v current 0", s.matches, s.current, want)
+	}
+
+	// The focused match rolling off moves the focus to the first match left
+	s = s.rolled(2)
+	if s.current != 0 || len(s.matches) != 1 || s.matches[0].line != 0 {
+		t.Errorf("rolled(2) = %v current %d, want the last match focused", s.matches, s.current)
+	}
+	if s = s.rolled(1); s.current != -1 || len(s.matches) != 0 {
+		t.Errorf("rolled(1) = %v current %d, want no matches", s.matches, s.current)
+	}
+}
+
+func TestHandleTaskOutput_MatchesNewLine(t *testing.T) {
+	m := model{
+		styles:   newStyles(),
+		search:   newOutputSearch(),
+		viewport: viewport.New(viewport.WithWidth(80), viewport.WithHeight(5)),
+		streams:  showStderrOnly,
+		taskRun:  taskRun{output: []outputLine{{text: "error a", display: "error a", stream: streamStderr}}},
+	}
+	m.search.re = compileSearch("error")
+	m.search.matches = findOutputMatches(m.shownOutput(), m.search.re)
+	m.search.current = 0
+
+	m = m.handleTaskOutput(taskOutputMsg{line: "error on stdout", stream: streamStdout})
+	m = m.handleTaskOutput(taskOutputMsg{line: "error b", stream: streamStderr})
+	want := []outputMatch{{line: 0, start: 0, end: 5}, {line: 1, start: 0, end: 5}}
+	if !slices.Equal(m.search.matches, want) || m.search.current != 0 {
+		t.Errorf("matches = %v current %d, want %v current 0", m.search.matches, m.search.current, want)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// outputIdleDelay is how long a task's output has to stall before the line
+// it is writing is shown and checked for a prompt.
+const outputIdleDelay = 500 * time.Millisecond
+
+var (
+	// questionPattern matches lines asking for an answer, like "Continue? [y/N]".
+	questionPattern = regexp.MustCompile(`(?i)(\[y(es)?/n(o)?\]|\(y(es)?/n(o)?\)|\?)$`)
+	// partialPromptPattern matches what a prompt that keeps the cursor on its
+	// line usually ends in, like "Password:" or "> ".
+	partialPromptPattern = regexp.MustCompile(`[:>$#]$`)
+)
+
+// taskIdleMsg is sent when a stream of a running task stopped producing
+// output for outputIdleDelay. partial is the unterminated line the task was
+// writing, if any, which tasks waiting on input usually end with.
+type taskIdleMsg struct {
+	run     int // id of the run whose output stalled
+	stream  outputStream
+	partial string
+}
+
+// taskInputSentMsg is sent when input typed in the output view has been
+// written to the task's stdin.
+type taskInputSentMsg struct {
+	err error
+}
+
+// readOutput calls line for each line read from r. When r stalls in the
+// middle of a line for idle, it calls pending with the text so far, and with
+// an empty string when it stalls after a complete line.
+func readOutput(r io.Reader, idle time.Duration, line, pending func(string)) {
+	chunks := make(chan []byte)
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, bufio.MaxScanTokenSize)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				chunks <- bytes.Clone(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var partial []byte
+	timer := time.NewTimer(idle)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				if len(partial) > 0 {
+					line(strings.TrimSuffix(string(partial), "\r"))
+				}
+				return
+			}
+			partial = append(partial, chunk...)
+			for {
+				i := bytes.IndexByte(partial, '\n')
+				if i < 0 {
+					break
+				}
+				line(strings.TrimSuffix(string(partial[:i]), "\r"))
+				partial = partial[i+1:]
+			}
+			// Don't let a stream that never writes a newline grow without bound
+			if len(partial) >= bufio.MaxScanTokenSize {
+				line(string(partial))
+				partial = nil
+			}
+			timer.Reset(idle)
+		case <-timer.C:
+			pending(string(partial))
+		}
+	}
+}
+
+// looksLikePrompt reports whether the last output of a task that went idle
+// looks like it asks for input. A line the task left unterminated is more
+// likely a prompt, so it is judged more leniently than a complete line.
+func looksLikePrompt(text string, partial bool) bool {
+	text = ansi.Strip(text)
+	// Progress bars redraw their line with carriage returns, only the last draw is shown
+	if i := strings.LastIndexByte(text, '\r'); i >= 0 {
+		text = text[i+1:]
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	if questionPattern.MatchString(text) {
+		return true
+	}
+	return partial && partialPromptPattern.MatchString(text)
+}
+
+// writeStdin writes a line of input to the stdin of a running task.
+func writeStdin(w io.Writer, input string) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := io.WriteString(w, input+"\n"); err != nil {
+			return taskInputSentMsg{err: fmt.Errorf("write to task stdin: %w", err)}
+		}
+		return taskInputSentMsg{}
+	}
+}
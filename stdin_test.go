@@ -0,0 +1,124 @@
+package main
+
+import (
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLooksLikePrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		partial bool
+		want    bool
+	}{
+		{name: "yes/no question", text: "Continue? [y/N]", want: true},
+		{name: "parenthesized yes/no", text: "Overwrite file (yes/no) ", want: true},
+		{name: "question mark", text: "\x1b[1mWhich environment?\x1b[0m", want: true},
+		{name: "log line", text: "Building project...", want: false},
+		{name: "colon on complete line", text: "Running tests:", want: false},
+		{name: "colon on partial line", text: "Password: ", partial: true, want: true},
+		{name: "shell style prompt", text: "> ", partial: true, want: true},
+		{name: "progress bar", text: "45% done?\r90% done", partial: true, want: false},
+		{name: "empty", text: "  ", partial: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := looksLikePrompt(tt.text, tt.partial); got != tt.want {
+				t.Errorf("looksLikePrompt(%q, %v) = %v, want %v", tt.text, tt.partial, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOutput(t *testing.T) {
+	r, w := io.Pipe()
+
+	var (
+		mu      sync.Mutex
+		lines   []string
+		pending []string
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readOutput(r, 20*time.Millisecond,
+			func(line string) { mu.Lock(); lines = append(lines, line); mu.Unlock() },
+			func(partial string) { mu.Lock(); pending = append(pending, partial); mu.Unlock() },
+		)
+	}()
+
+	waitForPending := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			found := slices.Contains(pending, want)
+			mu.Unlock()
+			if found {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("pending(%q) was not called, got %q", want, pending)
+	}
+
+	_, _ = io.WriteString(w, "first\r\nsec")
+	_, _ = io.WriteString(w, "ond\nContinue? [y/N] ")
+	waitForPending("Continue? [y/N] ")
+	_, _ = io.WriteString(w, "y\nlast")
+	_ = w.Close()
+	<-done
+
+	want := []string{"first", "second", "Continue? [y/N] y", "last"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestHandleTaskIdle(t *testing.T) {
+	_, stdin, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = stdin.Close() })
+
+	m := createTestModel(nil)
+	m.taskRunning = true
+	m.stdin = stdin
+	m.output = []outputLine{
+		{text: "Deploy to production?", stream: streamStdout},
+		{text: "warning: dirty tree", stream: streamStderr},
+	}
+
+	m = m.handleTaskIdle(taskIdleMsg{stream: streamStdout})
+	if !m.awaitingInput {
+		t.Error("task idle after a question should be awaiting input")
+	}
+
+	m = m.handleTaskIdle(taskIdleMsg{stream: streamStderr})
+	if m.awaitingInput {
+		t.Error("task idle after a warning should not be awaiting input")
+	}
+
+	m = m.handleTaskIdle(taskIdleMsg{stream: streamStdout, partial: "Password: "})
+	if !m.awaitingInput || m.partialOutput != "Password: " {
+		t.Errorf("awaitingInput = %v, partialOutput = %q after partial prompt", m.awaitingInput, m.partialOutput)
+	}
+
+	m = m.handleTaskOutput(taskOutputMsg{line: "Password: ok", stream: streamStdout})
+	if m.awaitingInput || m.partialOutput != "" {
+		t.Error("new output should clear the input hint")
+	}
+
+	m = m.closeStdin()
+	m = m.handleTaskIdle(taskIdleMsg{stream: streamStdout, partial: "Password: "})
+	if m.awaitingInput {
+		t.Error("task without stdin should not be awaiting input")
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// toolDetailLabelWidth is the width of the labels in the tool detail pane.
+const toolDetailLabelWidth = 14
+
+// toolDetailPane shows everything mise knows about the selected tool.
+type toolDetailPane struct {
+	open    bool
+	loading bool
+	tool    string
+	detail  loader.ToolDetail
+	err     error
+	notice  string // result of the last action, shown until the next key press
+}
+
+// openToolDetail opens the detail pane of the selected tool and loads its details.
+func (m model) openToolDetail() (model, tea.Cmd, bool) {
+	tool, ok := m.selectedToolRow()
+	if !ok {
+		return m, nil, true
+	}
+	m.logger.Debug("loading tool detail", "tool", tool.Name)
+	m.toolDetail = toolDetailPane{open: true, loading: true, tool: tool.Name}
+	return m, loader.LoadToolDetail(context.Background(), m.mise, tool.Name), true
+}
+
+// handleToolDetailLoaded shows the loaded details if the pane still shows their tool.
+func (m model) handleToolDetailLoaded(msg loader.ToolDetailLoadedMsg) model {
+	if !m.toolDetail.open || msg.Tool != m.toolDetail.tool {
+		return m
+	}
+	m.toolDetail.loading = false
+	if msg.Err != nil {
+		m.logger.Error("error loading tool detail", "tool", msg.Tool, "error", msg.Err)
+		m.toolDetail.err = msg.Err
+		return m
+	}
+	m.toolDetail.detail = msg.Detail
+	return m
+}
+
+// handleToolDetailKeys handles key presses while the tool detail pane is open.
+func (m model) handleToolDetailKeys(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	m.toolDetail.notice = ""
+	switch msg.String() {
+	case "y":
+		path := m.toolDetail.detail.InstallPath
+		if path == "" {
+			m.toolDetail.notice = m.styles.err.Render("the tool isn't installed")
+			return m, nil
+		}
+		m.toolDetail.notice = m.styles.success.Render("✓ Copied install path")
+		return m, tea.SetClipboard(path)
+	case "e":
+		requests := m.toolDetail.detail.RequestedBy
+		if len(requests) == 0 {
+			return m, nil
+		}
+		return m, m.openEditor(requests[0].SourcePath)
+	case keyEsc, "q":
+		m.toolDetail = toolDetailPane{}
+	}
+	return m, nil
+}
+
+// renderToolDetailView renders the tool detail pane.
+func (m model) renderToolDetailView() tea.View {
+	pane := m.toolDetail
+	sections := []string{m.styles.title.Render("Tool: " + pane.tool), ""}
+	switch {
+	case pane.loading:
+		sections = append(sections, m.styles.help.Render("Loading..."))
+	case pane.err != nil:
+		sections = append(sections, m.styles.err.Render(pane.err.Error()))
+	default:
+		sections = append(sections, m.renderToolDetail(pane.detail))
+	}
+	if pane.notice != "" {
+		sections = append(sections, "", pane.notice)
+	}
+	sections = append(sections, "", m.argInputHelp.View(newToolDetailKeyMap()))
+
+	v := tea.NewView(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	v.AltScreen = true
+	return v
+}
+
+// renderToolDetail renders the details of a tool as labelled rows.
+func (m model) renderToolDetail(detail loader.ToolDetail) string {
+	var rows []string
+	addRow := func(label, value string) {
+		if value == "" {
+			value = m.styles.help.Render("—")
+		}
+		rows = append(rows, m.styles.help.Width(toolDetailLabelWidth).Render(label)+value)
+	}
+
+	addRow("Backend", detail.Backend)
+	addRow("Description", detail.Description)
+	addRow("Install path", detail.InstallPath)
+	if detail.InstallPath != "" {
+		addRow("Disk usage", formatBytes(detail.DiskUsage))
+	}
+
+	versions := make([]string, len(detail.InstalledVersions))
+	for i, version := range detail.InstalledVersions {
+		versions[i] = version
+		if slices.Contains(detail.ActiveVersions, version) {
+			versions[i] = m.styles.success.Render(version + " (active)")
+		}
+	}
+	addRow("Installed", strings.Join(versions, ", "))
+
+	aliases := make([]string, len(detail.Aliases))
+	for i, alias := range detail.Aliases {
+		aliases[i] = alias.Alias + " → " + alias.Version
+	}
+	addRow("Aliases", strings.Join(aliases, ", "))
+
+	// The request in effect comes first, marked with a filled dot
+	if len(detail.RequestedBy) == 0 {
+		addRow("Requested by", "")
+	}
+	for i, request := range detail.RequestedBy {
+		label := ""
+		if i == 0 {
+			label = "Requested by"
+		}
+		line := fmt.Sprintf("%s → %s  %s", request.RequestedVersion, request.Version,
+			formatSourcePath(request.SourcePath))
+		if request.Active {
+			line = m.styles.success.Render("● ") + line
+		} else {
+			line = m.styles.help.Render("○ " + line)
+		}
+		addRow(label, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
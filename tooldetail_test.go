@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+func TestHandleToolDetailLoaded(t *testing.T) {
+	m := createTestModel(nil, withToolDetail("node"))
+
+	// Details of a tool the pane no longer shows are dropped
+	m = m.handleToolDetailLoaded(loader.ToolDetailLoadedMsg{Tool: "go"})
+	if !m.toolDetail.loading {
+		t.Fatal("details of another tool were shown")
+	}
+
+	m = m.handleToolDetailLoaded(loader.ToolDetailLoadedMsg{Tool: "node", Detail: loader.ToolDetail{
+		Name:              "node",
+		Backend:           "aqua:nodejs/node",
+		InstallPath:       "/data/mise/installs/node/22.1.0",
+		DiskUsage:         2048,
+		InstalledVersions: []string{"20.0.0", "22.1.0"},
+		ActiveVersions:    []string{"22.1.0"},
+		Aliases:           []loader.VersionAlias{{Alias: "lts", Version: "22"}},
+		RequestedBy: []loader.ToolRequest{
+			{Version: "22.1.0", RequestedVersion: "22", SourcePath: "/p/mise.toml", Active: true},
+		},
+	}})
+	if m.toolDetail.loading {
+		t.Fatal("pane is still loading")
+	}
+
+	view := ansi.Strip(m.renderToolDetail(m.toolDetail.detail))
+	for _, want := range []string{
+		"aqua:nodejs/node", "/data/mise/installs/node/22.1.0", "2.0 KiB", "22.1.0 (active)", "lts → 22",
+		"● 22 → 22.1.0  /p/mise.toml",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail pane is missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestHandleToolDetailKeys(t *testing.T) {
+	m := createTestModel(nil, withToolDetail("node"))
+	m = m.handleToolDetailLoaded(loader.ToolDetailLoadedMsg{Tool: "node", Err: errors.New("boom")})
+	if m.toolDetail.err == nil {
+		t.Fatal("load error was not kept")
+	}
+
+	next, cmd := m.handleToolDetailKeys(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	m = next.(model)
+	if cmd != nil || m.toolDetail.notice == "" {
+		t.Error("copying the path of a tool that isn't installed should only report it")
+	}
+
+	m.toolDetail.detail.InstallPath = "/data/mise/installs/node/22.1.0"
+	next, cmd = m.handleToolDetailKeys(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	m = next.(model)
+	if cmd == nil {
+		t.Error("copying the install path returned no clipboard command")
+	}
+
+	next, _ = m.handleToolDetailKeys(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if next.(model).toolDetail.open {
+		t.Error("Esc did not close the pane")
+	}
+}
+
+func TestUpdate_OpenAndCloseToolDetail(t *testing.T) {
+	m := createTestModel(nil)
+	m.tools = []loader.Tool{{Name: "go", Version: "1.25.0"}, {Name: "node", Version: "22.1.0"}}
+	m.filteredTools = m.tools
+	m.toolsTable = newTable(getToolsTableConfig(), m.toolRows(m.tools), false)
+
+	m, cmd := pressKeys(t, m, "tab", "down", "i")
+	if !m.toolDetail.open || !m.toolDetail.loading || m.toolDetail.tool != "node" || cmd == nil {
+		t.Fatalf("after i the pane is open = %v for %q, want the details of node loading",
+			m.toolDetail.open, m.toolDetail.tool)
+	}
+	next, _ := m.Update(loader.ToolDetailLoadedMsg{Tool: "node", Detail: loader.ToolDetail{Name: "node"}})
+	m = next.(model)
+	if m.toolDetail.loading {
+		t.Error("the loaded details weren't shown")
+	}
+
+	m, _ = pressKeys(t, m, keyEsc)
+	if m.toolDetail.open || m.focus != focusTools {
+		t.Errorf("after Esc the pane is open = %v, want it closed with the tools focused", m.toolDetail.open)
+	}
+}
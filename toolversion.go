@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// fuzzyLatest is the fuzzy version mise resolves to the newest release.
+const fuzzyLatest = "latest"
+
+// changeToolVersion opens the version list of the selected tool to change the
+// version its config file requests, skipping the tool and config steps.
+func (m model) changeToolVersion() (model, tea.Cmd, bool) {
+	tool, ok := m.selectedToolRow()
+	if !ok {
+		return m, nil, true
+	}
+	return m.startVersionChange(tool)
+}
+
+// startVersionChange loads the versions of tool to change its requested
+// version. Tools no config file requests have no version to change.
+func (m model) startVersionChange(tool loader.Tool) (model, tea.Cmd, bool) {
+	if tool.SourcePath == "" {
+		m.notice = m.styles.err.Render(tool.Name + " isn't requested by a config file, press a to add it to one")
+		return m, nil, true
+	}
+	m.logger.Debug("changing tool version", "tool", tool.Name, "requested", tool.RequestedVersion,
+		"config", tool.SourcePath)
+	m.pickerChange = tool
+	m.selectedTool = tool.Name
+	m.pickerState = pickerLoadingVersions
+	m.versionsLoading = true
+	return m, loader.LoadToolVersions(context.Background(), m.mise, m.toolCache(), tool.Name), true
+}
+
+// changingVersion reports whether the picker changes the version of an existing tool.
+func (m model) changingVersion() bool {
+	return m.pickerChange.Name != ""
+}
+
+// versionChoices are the versions the picker offers for a tool and how the tool uses them.
+type versionChoices struct {
+	versions    []loader.Version // newest first
+	installed   []string
+	requested   string // version the config requests, like 1.24
+	active      string // version in use, like 1.24.9
+	prereleases bool   // whether prereleases are listed
+}
+
+// newVersionChoices parses the loaded versions of a tool.
+func newVersionChoices(msg loader.VersionsLoadedMsg) versionChoices {
+	choices := versionChoices{installed: msg.Installed, requested: msg.Requested, active: msg.Active}
+	for _, v := range msg.Versions {
+		choices.versions = append(choices.versions, loader.ParseVersion(v))
+	}
+	return choices
+}
+
+// listed reports whether v is listed. The requested and active versions are
+// listed even when they are prereleases.
+func (c versionChoices) listed(v loader.Version) bool {
+	return c.prereleases || !v.IsPrerelease() || v.Raw == c.requested || v.Raw == c.active
+}
+
+// hiddenCount returns the number of prereleases that aren't listed.
+func (c versionChoices) hiddenCount() int {
+	hidden := 0
+	for _, v := range c.versions {
+		if !c.listed(v) {
+			hidden++
+		}
+	}
+	return hidden
+}
+
+// newest returns the newest version the fuzzy version resolves to. Like mise,
+// it prefers releases and falls back to prereleases when there are none.
+func (c versionChoices) newest(fuzzy string) string {
+	var prerelease string
+	for _, v := range c.versions {
+		if fuzzy != fuzzyLatest && v.Raw != fuzzy && !strings.HasPrefix(v.Raw, fuzzy+".") && v.Series != fuzzy {
+			continue
+		}
+		if !v.IsPrerelease() {
+			return v.Raw
+		}
+		if prerelease == "" {
+			prerelease = v.Raw
+		}
+	}
+	return prerelease
+}
+
+// items returns the versions to pick from. Fuzzy versions, which mise
+// resolves to their newest release, lead: latest, then a request of another
+// precision, like 22 for node. Versions follow grouped by their major.minor
+// series, each group headed by the series as a fuzzy version, and versions
+// that aren't semver come last. It also returns the index of the requested
+// version, or of the active one, to start on.
+func (c versionChoices) items() ([]list.Item, int) {
+	var series []string
+	groups := map[string][]string{}
+	var others []string
+	for _, v := range c.versions {
+		switch {
+		case !c.listed(v):
+		case !v.Semver:
+			others = append(others, v.Raw)
+		default:
+			if _, ok := groups[v.Series]; !ok {
+				series = append(series, v.Series)
+			}
+			groups[v.Series] = append(groups[v.Series], v.Raw)
+		}
+	}
+
+	items := []list.Item{versionItem{version: fuzzyLatest, fuzzy: true, note: c.newestNote(fuzzyLatest)}}
+	isVersion := func(version string) bool {
+		return slices.ContainsFunc(c.versions, func(v loader.Version) bool { return v.Raw == version })
+	}
+	if c.requested != "" && c.requested != fuzzyLatest && groups[c.requested] == nil && !isVersion(c.requested) {
+		items = append(items, versionItem{version: c.requested, fuzzy: true, note: c.newestNote(c.requested)})
+	}
+	for _, s := range series {
+		versions := groups[s]
+		// A series of its own version, like 22 when mise lists 22, needs no heading
+		grouped := len(versions) > 1 || versions[0] != s
+		if grouped {
+			note := c.newestNote(s)
+			if len(versions) > 1 {
+				note = fmt.Sprintf("%s · %d versions", note, len(versions))
+			}
+			items = append(items, versionItem{version: s, fuzzy: true, note: note})
+		}
+		for _, v := range versions {
+			items = append(items, versionItem{version: v, grouped: grouped})
+		}
+	}
+	for _, v := range others {
+		items = append(items, versionItem{version: v})
+	}
+
+	selected, activeIndex := -1, 0
+	for i, item := range items {
+		v, _ := item.(versionItem)
+		var notes []string
+		if v.version == c.requested {
+			notes = append(notes, "requested")
+			v.requested = true
+			selected = i
+		}
+		switch {
+		case v.fuzzy:
+		case v.version == c.active:
+			notes = append(notes, "current")
+			activeIndex = i
+		case slices.Contains(c.installed, v.version):
+			notes = append(notes, "installed")
+		}
+		if v.note != "" {
+			notes = append(notes, v.note)
+		}
+		v.note = strings.Join(notes, " · ")
+		items[i] = v
+	}
+	if selected < 0 {
+		selected = activeIndex
+	}
+	return items, selected
+}
+
+// newestNote describes what the fuzzy version resolves to.
+func (c versionChoices) newestNote(fuzzy string) string {
+	if newest := c.newest(fuzzy); newest != "" {
+		return "newest " + newest
+	}
+	return "no matching version"
+}
+
+// setVersionItems lists the version choices, starting on selected, or on the
+// version the tool requests when selected isn't listed.
+func (m model) setVersionItems(selected string) model {
+	items, index := m.versionChoices.items()
+	for i, item := range items {
+		if v, ok := item.(versionItem); ok && selected != "" && v.version == selected {
+			index = i
+		}
+	}
+	m.versionList.SetItems(items)
+	m.versionList.Select(index)
+
+	title := fmt.Sprintf("Select version for: %s", m.selectedTool)
+	if m.changingVersion() {
+		title = fmt.Sprintf("Change version of %s in %s", m.selectedTool, formatSourcePath(m.pickerChange.SourcePath))
+	}
+	if hidden := m.versionChoices.hiddenCount(); hidden > 0 {
+		title += fmt.Sprintf(" (prereleases hidden: %d)", hidden)
+	}
+	m.versionList.Title = title
+	return m
+}
+
+// togglePrereleases lists or hides prereleases, keeping the cursor on the selected version.
+func (m model) togglePrereleases() model {
+	m.versionChoices.prereleases = !m.versionChoices.prereleases
+	selected := ""
+	if v, ok := m.versionList.SelectedItem().(versionItem); ok {
+		selected = v.version
+	}
+	return m.setVersionItems(selected)
+}
+
+// installChangedVersion requests version of the tool being changed in the
+// config file that requested it before.
+func (m model) installChangedVersion(version string) (model, tea.Cmd) {
+	m.selectedVersion = version
+	m.pickerState = pickerInstalling
+	m.logger.Debug("changing requested version", "tool", m.selectedTool, "version", version,
+		"config", m.pickerChange.SourcePath)
+	return m, loader.InstallTool(context.Background(), m.mise, m.selectedTool, version, m.pickerChange.SourcePath)
+}
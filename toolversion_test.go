@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+)
+
+// versionTitles returns the versions of items.
+func versionTitles(items []list.Item) []string {
+	var versions []string
+	for _, item := range items {
+		versions = append(versions, item.(versionItem).version)
+	}
+	return versions
+}
+
+func TestVersionChoicesItems(t *testing.T) {
+	choices := newVersionChoices(loader.VersionsLoadedMsg{
+		Versions:  []string{"1.26rc1", "1.25.4", "1.25.3", "1.24.9", "1.24.0", "tip"},
+		Installed: []string{"1.25.3", "1.24.9"},
+		Requested: "1.24",
+		Active:    "1.24.9",
+	})
+
+	items, selected := choices.items()
+	want := []string{"latest", "1.25", "1.25.4", "1.25.3", "1.24", "1.24.9", "1.24.0", "tip"}
+	if got := versionTitles(items); !slices.Equal(got, want) {
+		t.Fatalf("items() = %q, want %q", got, want)
+	}
+	if selected != 4 {
+		t.Errorf("selected = %d, want the requested 1.24", selected)
+	}
+	if hidden := choices.hiddenCount(); hidden != 1 {
+		t.Errorf("hiddenCount() = %d, want 1", hidden)
+	}
+
+	notes := map[int]string{
+		0: "newest 1.25.4",
+		1: "newest 1.25.4 · 2 versions",
+		3: "installed",
+		4: "requested · newest 1.24.9 · 2 versions",
+		5: "current",
+	}
+	for i, note := range notes {
+		if got := items[i].(versionItem).note; got != note {
+			t.Errorf("note of %s = %q, want %q", want[i], got, note)
+		}
+	}
+	if v := items[4].(versionItem); v.Title() != "● 1.24" {
+		t.Errorf("Title() = %q, want the request marked", v.Title())
+	}
+	if v := items[5].(versionItem); v.Title() != "  1.24.9" {
+		t.Errorf("Title() = %q, want the version indented below its series", v.Title())
+	}
+
+	choices.prereleases = true
+	items, _ = choices.items()
+	want = []string{"latest", "1.26", "1.26rc1", "1.25", "1.25.4", "1.25.3", "1.24", "1.24.9", "1.24.0", "tip"}
+	if got := versionTitles(items); !slices.Equal(got, want) {
+		t.Errorf("items() with prereleases = %q, want %q", got, want)
+	}
+	if got := items[1].(versionItem).note; got != "newest 1.26rc1" {
+		t.Errorf("a series of prereleases resolves to %q", got)
+	}
+
+	// A fuzzy request of another precision is offered after latest
+	choices = newVersionChoices(loader.VersionsLoadedMsg{
+		Versions: []string{"22.1.0", "20.3.0"}, Requested: "22", Active: "22.1.0",
+	})
+	items, selected = choices.items()
+	if v := items[1].(versionItem); v.version != "22" || selected != 1 {
+		t.Errorf("items() offered %q selected %d, want the requested 22 selected", v.version, selected)
+	}
+
+	// Without a request the active version is selected
+	choices = newVersionChoices(loader.VersionsLoadedMsg{Versions: []string{"1.25.4", "1.25.3"}, Active: "1.25.3"})
+	if _, selected = choices.items(); selected != 3 {
+		t.Errorf("selected = %d, want the active 1.25.3 at 3", selected)
+	}
+}
+
+func TestTogglePrereleases(t *testing.T) {
+	m := createTestModel(nil)
+	m.selectedTool = "go"
+	m.pickerState = pickerLoadingVersions
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Versions: []string{"1.26rc1", "1.25.4"}})
+	if len(m.versionList.Items()) != 3 || !strings.Contains(m.versionList.Title, "prereleases hidden: 1") {
+		t.Fatalf("items = %q title %q, want the rc hidden", versionTitles(m.versionList.Items()), m.versionList.Title)
+	}
+
+	m.versionList.Select(2)
+	m, _ = m.handleVersionListKeys(tea.KeyPressMsg{Code: 'p', Text: "p"})
+	if len(m.versionList.Items()) != 5 || strings.Contains(m.versionList.Title, "hidden") {
+		t.Errorf("items = %q, want the rc listed", versionTitles(m.versionList.Items()))
+	}
+	if v := m.versionList.SelectedItem().(versionItem); v.version != "1.25.4" {
+		t.Errorf("selected %q, want the cursor to stay on 1.25.4", v.version)
+	}
+}
+
+func TestChangeToolVersion(t *testing.T) {
+	m := createTestModel(nil)
+	tool := loader.Tool{Name: "go", Version: "1.24.9", RequestedVersion: "1.24", SourcePath: "/p/mise.toml"}
+
+	m, _, _ = m.startVersionChange(tool)
+	if m.pickerState != pickerLoadingVersions || !m.changingVersion() {
+		t.Fatalf("pickerState = %v, want the versions of go loading", m.pickerState)
+	}
+
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Versions: []string{"1.25.4", "1.24.9"}})
+	if m.pickerState != pickerSelectVersion {
+		t.Fatalf("pickerState = %v, want the version list", m.pickerState)
+	}
+	if v := m.versionList.SelectedItem().(versionItem); v.version != "1.24" {
+		t.Errorf("selected %q, want the requested 1.24", v.version)
+	}
+
+	// Enter installs into the same config without asking for one
+	m.versionList.Select(1)
+	m, cmd := m.handleVersionListKeys(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.pickerState != pickerInstalling || m.selectedVersion != "1.25" || cmd == nil {
+		t.Errorf("pickerState = %v version %q, want 1.25 installing", m.pickerState, m.selectedVersion)
+	}
+
+	m, _ = m.handleToolInstalled(loader.ToolInstalledMsg{Tool: "go", Version: "1.25"})
+	if m.changingVersion() {
+		t.Error("the version change was not reset after installing")
+	}
+
+	// Tools no config file requests can't change version
+	m, cmd, _ = m.startVersionChange(loader.Tool{Name: "node", Version: "22.1.0"})
+	if m.pickerState != pickerClosed || m.changingVersion() || cmd != nil || m.notice == "" {
+		t.Errorf("pickerState = %v notice %q, want the change refused", m.pickerState, m.notice)
+	}
+
+	// Esc closes the picker rather than going to the tool list
+	m, _, _ = m.startVersionChange(tool)
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Versions: []string{"1.24.9"}})
+	m, _ = m.handleVersionListKeys(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.pickerState != pickerClosed || m.changingVersion() {
+		t.Errorf("pickerState = %v, want the picker closed", m.pickerState)
+	}
+}
+
+func TestVersionsRefreshed(t *testing.T) {
+	m := createTestModel(nil)
+	m.selectedTool = "go"
+	m.pickerState = pickerLoadingVersions
+
+	// Stale versions are listed and refreshed
+	m, cmd := m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Versions: []string{"1.25.3"}, Stale: true})
+	if m.pickerState != pickerSelectVersion || cmd == nil {
+		t.Fatalf("pickerState = %v, want stale versions listed while they refresh", m.pickerState)
+	}
+
+	m.versionList.Select(2)
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Versions: []string{"1.25.4", "1.25.3"}})
+	if got := versionTitles(m.versionList.Items()); !slices.Contains(got, "1.25.4") {
+		t.Fatalf("items = %q, want the refreshed versions", got)
+	}
+	if v := m.versionList.SelectedItem().(versionItem); v.version != "1.25.3" {
+		t.Errorf("selected %q, want the cursor to stay on 1.25.3", v.version)
+	}
+
+	// A failed refresh, or versions of another tool, leave the list alone
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "go", Err: errors.New("offline")})
+	m, _ = m.handleVersionsLoaded(loader.VersionsLoadedMsg{Tool: "node", Versions: []string{"22.1.0"}})
+	got := versionTitles(m.versionList.Items())
+	if !slices.Contains(got, "1.25.4") || m.pickerState != pickerSelectVersion {
+		t.Errorf("items = %q, want the refreshed go versions kept", got)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/table"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
 )
 
 // formatSourcePath formats a config file path for display.
@@ -49,7 +52,9 @@ const (
 
 const (
 	// Column width constants.
+	colWidthMark        = 3
 	colWidthName        = 20
+	colWidthAliases     = 12
 	colWidthDescription = 40
 	colWidthVersion     = 15
 	colWidthValue       = 50
@@ -80,9 +85,18 @@ const (
 	// pickerListPadding is the space reserved for header/footer in picker views.
 	pickerListPadding = 4
 
+	// wizardScriptHeight is the number of lines shown for the run script in the new task wizard.
+	wizardScriptHeight = 6
+
+	// favoriteMarker prefixes the names of favorite tasks in the tasks table.
+	favoriteMarker = "★ "
+
 	// maxOutputLines is the maximum number of output lines to keep in memory.
 	// When this limit is exceeded, older lines are dropped in a rolling buffer fashion.
 	maxOutputLines = 10000
+
+	// outputTimeFormat is the timestamp format for output lines.
+	outputTimeFormat = "15:04:05.000"
 )
 
 // tableConfig holds configuration for creating a table.
@@ -93,22 +107,54 @@ type tableConfig struct {
 
 // styles holds the UI styles used throughout the application.
 type styles struct {
-	title    lipgloss.Style
-	dimTitle lipgloss.Style
-	help     lipgloss.Style
-	err      lipgloss.Style
-	success  lipgloss.Style
+	title      lipgloss.Style
+	dimTitle   lipgloss.Style
+	help       lipgloss.Style
+	err        lipgloss.Style
+	success    lipgloss.Style
+	hiddenTask lipgloss.Style
+	match      lipgloss.Style
+
+	searchMatch   lipgloss.Style
+	searchCurrent lipgloss.Style
+	stderr        lipgloss.Style
+	inputHint     lipgloss.Style
 }
 
 // newStyles creates the default UI styles.
 func newStyles() styles {
 	return styles{
-		title:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("255")),
-		dimTitle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("241")),
-		help:     lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
-		err:      lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
-		success:  lipgloss.NewStyle().Foreground(lipgloss.Color("82")),
+		title:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("255")),
+		dimTitle:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("241")),
+		help:       lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		err:        lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		success:    lipgloss.NewStyle().Foreground(lipgloss.Color("82")),
+		hiddenTask: lipgloss.NewStyle().Foreground(lipgloss.Color("241")),
+		match:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+
+		searchMatch:   lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("178")),
+		searchCurrent: lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("212")),
+		stderr:        lipgloss.NewStyle().Foreground(lipgloss.Color("209")),
+		inputHint:     lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("178")),
+	}
+}
+
+// fitStyledCell renders value with render, shortening it until the result fits
+// in width as measured by runewidth. The table truncates cells with runewidth,
+// which counts ANSI escape sequences as visible characters and would otherwise
+// cut styled cells short or split their escape codes.
+func fitStyledCell(value string, width int, render func(string) string) string {
+	runes := []rune(value)
+	for n := len(runes); n > 0; n-- {
+		text := string(runes[:n])
+		if n < len(runes) {
+			text += "…"
+		}
+		if styled := render(text); runewidth.StringWidth(styled) <= width {
+			return styled
+		}
 	}
+	return ""
 }
 
 // renderTitle renders a section title with focus state.
@@ -123,7 +169,9 @@ func (s styles) renderTitle(name string, focused bool) string {
 func getTasksTableConfig() tableConfig {
 	return tableConfig{
 		columns: []table.Column{
+			{Title: "", Width: colWidthMark},
 			{Title: "Name", Width: colWidthName},
+			{Title: "Aliases", Width: colWidthAliases},
 			{Title: "Description", Width: colWidthDescription},
 			{Title: "Source", Width: colWidthSource},
 		},
@@ -274,8 +322,14 @@ func updateTableLayout(m model) model {
 func (m model) renderArgInputView() tea.View {
 	title := m.styles.title.Render(fmt.Sprintf("Run task: %s", m.argInputTask))
 	prompt := m.styles.help.Render("Enter arguments for the task:")
+	envPrompt := m.styles.help.Render("Environment overrides (KEY=VALUE ...):")
 	helpView := m.argInputHelp.View(m.argInputKeys)
 
+	var errLine string
+	if m.argInputErr != nil {
+		errLine = m.styles.err.Render(m.argInputErr.Error())
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
@@ -283,6 +337,9 @@ func (m model) renderArgInputView() tea.View {
 		prompt,
 		m.argInput.View(),
 		"",
+		envPrompt,
+		m.envInput.View(),
+		errLine,
 		helpView,
 	)
 
@@ -291,13 +348,182 @@ func (m model) renderArgInputView() tea.View {
 	return v
 }
 
-// renderFilterInput renders the filter input field.
-func (m model) renderFilterInput() string {
-	if !m.filterActive {
-		return ""
+// renderWizardView renders the new task wizard.
+func (m model) renderWizardView() tea.View {
+	title := m.styles.title.Render("New task")
+
+	// Summarize answers from completed steps
+	var summary []string
+	addAnswer := func(label, value string) {
+		summary = append(summary, m.styles.help.Render(label+": ")+value)
+	}
+	if m.wizardStep > wizardName {
+		addAnswer("Name", m.wizardSpec.Name)
+	}
+	if m.wizardStep > wizardDescription && m.wizardSpec.Description != "" {
+		addAnswer("Description", m.wizardSpec.Description)
+	}
+	if m.wizardStep > wizardRun {
+		addAnswer("Run", strings.ReplaceAll(m.wizardSpec.Run, "\n", "⏎ "))
+	}
+	if m.wizardStep > wizardDepends && len(m.wizardSpec.Depends) > 0 {
+		addAnswer("Depends", strings.Join(m.wizardSpec.Depends, ", "))
+	}
+
+	var body string
+	switch m.wizardStep {
+	case wizardName:
+		body = lipgloss.JoinVertical(lipgloss.Left, m.styles.help.Render("Task name:"), m.wizardInput.View())
+	case wizardDescription:
+		body = lipgloss.JoinVertical(lipgloss.Left, m.styles.help.Render("Description:"), m.wizardInput.View())
+	case wizardRun:
+		body = lipgloss.JoinVertical(lipgloss.Left, m.styles.help.Render("Run command or script:"), m.wizardScript.View())
+	case wizardDepends:
+		body = lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.styles.help.Render("Depends on (comma separated):"),
+			m.wizardInput.View(),
+		)
+	case wizardTarget:
+		body = m.wizardTargets.View()
+	case wizardWriting:
+		body = fmt.Sprintf("Writing %s...", m.wizardSpec.Name)
+	case wizardClosed:
+	}
+
+	sections := []string{title, ""}
+	if len(summary) > 0 {
+		sections = append(sections, lipgloss.JoinVertical(lipgloss.Left, summary...), "")
+	}
+	sections = append(sections, body)
+	if m.wizardErr != nil {
+		sections = append(sections, "", m.styles.err.Render(fmt.Sprintf("✗ %v", m.wizardErr)))
+	}
+	sections = append(sections, "", m.wizardHelp.View(m.wizardKeys))
+
+	v := tea.NewView(lipgloss.JoinVertical(lipgloss.Left, sections...))
+	v.AltScreen = true
+	return v
+}
+
+// renderRunStatus renders the state of the task in the output view header:
+// the live elapsed time while it runs, then how it exited, how long it took
+// and the resources it used, compared to the previous run of the same task.
+func (m model) renderRunStatus() string {
+	if m.taskRunning {
+		elapsed := formatDuration(time.Since(m.runStartedAt))
+		if m.taskStopping {
+			return m.styles.err.Render(m.taskSpinner.View() + " Stopping... " + elapsed)
+		}
+		return m.styles.dimTitle.Render(m.taskSpinner.View() + " Running... " + elapsed)
+	}
+
+	stats := m.runStats
+	var status string
+	switch {
+	case stats.signal != "":
+		status = m.styles.err.Render(fmt.Sprintf("✗ Killed by %s after %s", stats.signal, formatDuration(stats.duration)))
+	case m.taskErr != nil && stats.exitCode > 0:
+		status = m.styles.err.Render(fmt.Sprintf("✗ Failed with exit code %d after %s",
+			stats.exitCode, formatDuration(stats.duration)))
+	case m.taskErr != nil:
+		status = m.styles.err.Render(fmt.Sprintf("✗ Failed: %v", m.taskErr))
+	case m.dryRun:
+		return m.styles.success.Render("✓ Preview complete, nothing was executed")
+	default:
+		status = m.styles.success.Render("✓ Completed in " + formatDuration(stats.duration))
+	}
+
+	var details []string
+	if stats.cpuTime > 0 {
+		details = append(details, "cpu "+formatDuration(stats.cpuTime))
+	}
+	if stats.peakRSS > 0 {
+		details = append(details, "peak rss "+formatBytes(stats.peakRSS))
+	}
+	if prev := m.previousRun; prev.Duration > 0 {
+		details = append(details, "previous run "+formatDuration(prev.Duration))
+	}
+	if len(details) == 0 {
+		return status
+	}
+	return status + "  " + m.styles.help.Render(strings.Join(details, " · "))
+}
+
+// formatDuration formats d for display, with millisecond precision below a
+// second and tenths of a second below a minute.
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String() //nolint:mnd // tenths of a second
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// formatBytes formats a byte count with a binary unit, like 12.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// renderOutputBar renders the line below the output view header: the save
+// path input, the task input, the search input and match counter, a hint that
+// the task waits on input, or the last save or copy result.
+func (m model) renderOutputBar() string {
+	if m.saveInputActive {
+		prompt := m.styles.help.Render("Save to:")
+		return lipgloss.JoinHorizontal(lipgloss.Left, prompt, " ", m.saveInput.View())
+	}
+	if m.stdinInputActive {
+		// Prompts that keep the cursor on their line aren't in the output yet
+		prompt := m.styles.help.Render("Input:")
+		if m.partialOutput != "" {
+			prompt = strings.TrimSpace(ansi.Strip(m.partialOutput))
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Left, prompt, " ", m.stdinInput.View())
+	}
+	if !m.search.editing && !m.search.applied() {
+		if m.outputNotice == "" && m.awaitingInput {
+			hint := "Task may be waiting for input, press i to answer"
+			if m.partialOutput != "" {
+				hint = strings.TrimSpace(ansi.Strip(m.partialOutput)) + "  " + hint
+			}
+			return m.styles.inputHint.Render("⌨ " + hint)
+		}
+		return m.outputNotice
+	}
+	var counter string
+	switch {
+	case !m.search.applied():
+	case len(m.search.matches) == 0:
+		counter = m.styles.err.Render("no matches")
+	default:
+		counter = m.styles.help.Render(fmt.Sprintf("%d/%d", m.search.current+1, len(m.search.matches)))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, m.search.input.View(), "  ", counter)
+}
+
+// withFilterInput appends the filter input of the given table below its title
+// while the filter is applied.
+func (m model) withFilterInput(title string, section int) string {
+	f := m.filters[section]
+	if !f.applied() {
+		return title
 	}
 	prompt := m.styles.help.Render("Filter:")
-	return lipgloss.JoinHorizontal(lipgloss.Left, prompt, " ", m.filterInput.View())
+	input := lipgloss.JoinHorizontal(lipgloss.Left, prompt, " ", f.input.View())
+	return lipgloss.JoinVertical(lipgloss.Left, title, input)
 }
 
 // updateTableWidths adjusts table widths based on the current terminal width.
@@ -309,10 +535,12 @@ func updateTableWidths(m model) model {
 	// Use available width (with some padding for borders)
 	availableWidth := m.windowWidth - tablePadding
 
-	// Tasks table: Name + Description + Source columns
+	// Tasks table: Mark + Name + Aliases + Description + Source columns
 	// Description gets 60% of flexible space, Source gets 40%
 	tasksNameWidth := colWidthName
-	flexibleWidth := availableWidth - tasksNameWidth - columnPadding*2 //nolint:mnd // 2 column paddings
+	tasksAliasesWidth := colWidthAliases
+	flexibleWidth := availableWidth - colWidthMark - tasksNameWidth - tasksAliasesWidth -
+		columnPadding*4 //nolint:mnd // 4 column paddings
 
 	// Description gets 60% of remaining width, minimum 40 chars
 	tasksDescWidth := max(
@@ -327,18 +555,22 @@ func updateTableWidths(m model) model {
 	)
 
 	m.tasksTable.SetColumns([]table.Column{
+		{Title: "", Width: colWidthMark},
 		{Title: "Name", Width: tasksNameWidth},
+		{Title: "Aliases", Width: tasksAliasesWidth},
 		{Title: "Description", Width: tasksDescWidth},
 		{Title: "Source", Width: tasksSourceWidth},
 	})
 	m.tasksTable.SetWidth(availableWidth)
+	// Styled cells are fitted to column widths, so rebuild them for the new widths
+	m.tasksTable.SetRows(m.taskRows(m.filteredTasks))
 
 	// Tools table: Name + Version + Requested + Source columns
 	toolsNameWidth := colWidthName
 	toolsVersionWidth := colWidthVersion
 	toolsRequestedWidth := colWidthVersion
 	toolsSourceWidth := max(
-		availableWidth-toolsNameWidth-toolsVersionWidth-toolsRequestedWidth-columnPadding*3,
+		availableWidth-toolsNameWidth-toolsVersionWidth-toolsRequestedWidth-columnPadding*3, //nolint:mnd // 3 column paddings
 		colWidthSource,
 	)
 	m.toolsTable.SetColumns([]table.Column{
@@ -348,6 +580,7 @@ func updateTableWidths(m model) model {
 		{Title: "Source", Width: toolsSourceWidth},
 	})
 	m.toolsTable.SetWidth(availableWidth)
+	m.toolsTable.SetRows(m.toolRows(m.filteredTools))
 
 	// EnvVars table: Name + Value columns
 	envNameWidth := colWidthEnvName
@@ -357,6 +590,7 @@ func updateTableWidths(m model) model {
 		{Title: "Value", Width: envValueWidth},
 	})
 	m.envVarsTable.SetWidth(availableWidth)
+	m.envVarsTable.SetRows(m.envVarRows(m.filteredEnvVars))
 
 	return m
 }
@@ -1,6 +1,12 @@
 package main
 
-import "testing"
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
 
 func TestCalculateTableHeights(t *testing.T) {
 	tests := []struct {
@@ -142,3 +148,89 @@ func TestCalculateTableHeights_MinimumGuarantee(t *testing.T) {
 		}
 	}
 }
+
+func TestFitStyledCell(t *testing.T) {
+	wrap := func(s string) string { return "\x1b[2m" + s + "\x1b[0m" }
+
+	tests := []struct {
+		name     string
+		value    string
+		width    int
+		render   func(string) string
+		wantText string
+	}{
+		{
+			name:     "unstyled value that fits is unchanged",
+			value:    "build",
+			width:    10,
+			render:   func(s string) string { return s },
+			wantText: "build",
+		},
+		{
+			name:     "styled value that fits keeps every rune",
+			value:    "build",
+			width:    20,
+			render:   wrap,
+			wantText: "build",
+		},
+		{
+			name:     "styled value is shortened to leave room for escape codes",
+			value:    "build",
+			width:    10,
+			render:   wrap,
+			wantText: "bui…", // escape codes measure 6 columns, leaving 4 for text
+		},
+		{
+			name:     "no room at all returns empty",
+			value:    "build",
+			width:    3,
+			render:   wrap,
+			wantText: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fitStyledCell(tt.value, tt.width, tt.render)
+			if runewidth.StringWidth(got) > tt.width {
+				t.Errorf("fitStyledCell() width = %d, want <= %d", runewidth.StringWidth(got), tt.width)
+			}
+			plain := strings.NewReplacer("\x1b[2m", "", "\x1b[0m", "").Replace(got)
+			if plain != tt.wantText {
+				t.Errorf("fitStyledCell() text = %q, want %q", plain, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 1234567 * time.Nanosecond, want: "1ms"},
+		{d: 2345 * time.Millisecond, want: "2.3s"},
+		{d: 83*time.Second + 600*time.Millisecond, want: "1m24s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 512, want: "512 B"},
+		{n: 1536, want: "1.5 KiB"},
+		{n: 120 * 1024 * 1024, want: "120.0 MiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
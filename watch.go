@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/rshep3087/prep/internal/loader"
+	"github.com/rshep3087/prep/internal/watcher"
+)
+
+// taskWatch re-runs the task shown in the output view when its sources change.
+type taskWatch struct {
+	id      int              // tells messages of a stopped watch apart
+	task    string           // task that is re-run
+	args    []string         // arguments it is re-run with
+	env     []string         // environment overrides it is re-run with
+	sources []string         // globs of the watched files
+	watcher *watcher.Watcher // nil while not watching
+	runs    int              // runs started since the watch started
+	last    runStats         // stats of the last run that wasn't cancelled by the watch
+	lastErr error            // error of that run
+	hasLast bool             // whether a run has finished yet
+	rerun   bool             // whether to run again once the cancelled run has stopped
+}
+
+// active reports whether the watch is watching.
+func (w taskWatch) active() bool {
+	return w.watcher != nil
+}
+
+// sourcesChangedMsg is sent when sources of the watched task changed.
+type sourcesChangedMsg struct {
+	watch int
+	paths []string
+}
+
+// watchSelectedTask runs the selected task and runs it again whenever its sources change.
+func (m model) watchSelectedTask() (model, tea.Cmd, bool) {
+	task, ok := m.selectedTask()
+	if !ok {
+		return m, nil, true
+	}
+	m, cmd := m.guardRun([]loader.Task{task}, func(m model) (model, tea.Cmd) {
+		m, cmd := m.startTask(task.Name, nil)
+		m = m.watchTask(task, nil, nil)
+		if m.watch.active() {
+			m.watch.runs = 1
+		}
+		return m, cmd
+	})
+	return m, cmd, true
+}
+
+// watchTask starts watching the sources of task, which the output view
+// shows running with args and env. Problems are reported in the output view.
+func (m model) watchTask(task loader.Task, args, env []string) model {
+	m = m.stopWatch()
+	if len(task.Sources) == 0 {
+		m.outputNotice = m.styles.err.Render(task.Name + " declares no sources to watch")
+		return m
+	}
+
+	m.lastWatchID++
+	id := m.lastWatchID
+	w, err := watcher.StartFileWatcher(nil, m.sender,
+		watcher.WithMessage(func(paths []string) tea.Msg { return sourcesChangedMsg{watch: id, paths: paths} }),
+	)
+	if err == nil {
+		if err = w.AddGlobs(taskDir(task, m.cwd), task.Sources...); err != nil {
+			watcher.Close(w)
+		}
+	}
+	if err != nil {
+		m.logger.Error("error watching task sources", "task", task.Name, "error", err)
+		m.outputNotice = m.styles.err.Render(fmt.Sprintf("can't watch %s: %v", task.Name, err))
+		return m
+	}
+
+	m.logger.Debug("watching task sources", "task", task.Name, "sources", task.Sources)
+	m.watch = taskWatch{id: id, task: task.Name, args: args, env: env, sources: task.Sources, watcher: w}
+	return m
+}
+
+// stopWatch stops re-running the task on changes.
+func (m model) stopWatch() model {
+	if m.watch.active() {
+		m.logger.Debug("stopped watching task sources", "task", m.watch.task)
+		watcher.Close(m.watch.watcher)
+	}
+	m.watch = taskWatch{}
+	return m
+}
+
+// toggleWatch starts or stops watching the sources of the task in the output view.
+func (m model) toggleWatch() model {
+	if m.watch.active() {
+		m = m.stopWatch()
+		m.outputNotice = m.styles.help.Render("stopped watching")
+		return m
+	}
+	if m.batch.active() || m.dryRun {
+		m.outputNotice = m.styles.err.Render("only single task runs can be watched")
+		return m
+	}
+	idx := slices.IndexFunc(m.tasks, func(t loader.Task) bool { return t.Name == m.runningTask })
+	if idx < 0 {
+		return m
+	}
+	m = m.watchTask(m.tasks[idx], m.runningArgs, m.runningEnv)
+	if m.watch.active() {
+		m.watch.runs = 1
+	}
+	return m
+}
+
+// handleSourcesChanged re-runs the watched task. A run in progress is
+// cancelled first and the task runs again once it has stopped.
+func (m model) handleSourcesChanged(msg sourcesChangedMsg) (model, tea.Cmd) {
+	if !m.watch.active() || msg.watch != m.watch.id {
+		return m, nil
+	}
+	m.logger.Debug("task sources changed", "task", m.watch.task, "paths", msg.paths)
+	if !m.taskRunning {
+		return m.rerunWatchedTask()
+	}
+	if !m.watch.rerun && m.cancelFunc != nil {
+		m.watch.rerun = true
+		m.cancelFunc()
+		m.taskStopping = true
+	}
+	return m, nil
+}
+
+// recordWatchedRun keeps the result of a finished run for the output header.
+// Runs the watch cancelled to start over don't count.
+func (m model) recordWatchedRun(msg taskDoneMsg) model {
+	if !m.watch.active() || m.watch.rerun {
+		return m
+	}
+	m.watch.last = msg.stats
+	m.watch.lastErr = msg.err
+	m.watch.hasLast = true
+	return m
+}
+
+// rerunWatchedTask starts the next run of the watched task.
+func (m model) rerunWatchedTask() (model, tea.Cmd) {
+	m.watch.rerun = false
+	m.watch.runs++
+	return m.startTask(m.watch.task, m.watch.env, m.watch.args...)
+}
+
+// renderWatchStatus renders the watch for the output view header: how many
+// times the task ran and how the last finished run went.
+func (m model) renderWatchStatus() string {
+	if !m.watch.active() {
+		return ""
+	}
+	status := m.styles.help.Render(fmt.Sprintf("watching %d sources · run #%d", len(m.watch.sources), m.watch.runs))
+	if !m.watch.hasLast {
+		return status
+	}
+	last := m.watch.last
+	switch {
+	case last.signal != "":
+		return status + m.styles.help.Render(" · last ") + m.styles.err.Render("✗ "+last.signal)
+	case m.watch.lastErr != nil && last.exitCode > 0:
+		return status + m.styles.help.Render(" · last ") + m.styles.err.Render(fmt.Sprintf("✗ exit %d", last.exitCode))
+	case m.watch.lastErr != nil:
+		return status + m.styles.help.Render(" · last ") + m.styles.err.Render("✗ failed")
+	}
+	return status + m.styles.help.Render(" · last ") + m.styles.success.Render("✓ "+formatDuration(last.duration))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleSourcesChanged_RestartsRunningTask(t *testing.T) {
+	cancelled := 0
+	m := createTestModel(nil, withWatch(t, "test"))
+	m.cancelFunc = func() { cancelled++ }
+
+	// Changes while the run stops don't cancel it again
+	m, _ = m.handleSourcesChanged(sourcesChangedMsg{watch: 1, paths: []string{"a.go"}})
+	m, _ = m.handleSourcesChanged(sourcesChangedMsg{watch: 1, paths: []string{"b.go"}})
+	if cancelled != 1 || !m.watch.rerun || !m.taskStopping {
+		t.Fatalf("cancelled = %d, rerun = %v, stopping = %v, want the run cancelled once",
+			cancelled, m.watch.rerun, m.taskStopping)
+	}
+
+	next, cmd, _ := m.handleRunMsg(taskDoneMsg{run: 1, err: errors.New("signal: interrupt"), stats: runStats{signal: "interrupt"}})
+	if cmd == nil || !next.taskRunning || next.runID == 1 {
+		t.Fatalf("task was not started again: running = %v, runID = %d", next.taskRunning, next.runID)
+	}
+	t.Cleanup(func() { _ = next.closeStdin() })
+	if next.watch.runs != 2 || next.watch.rerun {
+		t.Errorf("runs = %d, rerun = %v, want second run started", next.watch.runs, next.watch.rerun)
+	}
+	if next.watch.hasLast {
+		t.Error("the run cancelled by the watch should not count as the last result")
+	}
+}
+
+func TestHandleSourcesChanged_IgnoresStoppedWatch(t *testing.T) {
+	m := createTestModel(nil, withWatch(t, "test"))
+	m, cmd := m.handleSourcesChanged(sourcesChangedMsg{watch: 2})
+	if cmd != nil || m.watch.rerun {
+		t.Error("a message of another watch should be ignored")
+	}
+
+	m = m.stopWatch()
+	m.taskRunning = false
+	if _, cmd = m.handleSourcesChanged(sourcesChangedMsg{watch: 1}); cmd != nil {
+		t.Error("changes after the watch stopped should be ignored")
+	}
+}
+
+func TestRecordWatchedRun(t *testing.T) {
+	m := createTestModel(nil, withWatch(t, "lint"))
+	m = m.recordWatchedRun(taskDoneMsg{run: 1, err: errors.New("exit status 1"), stats: runStats{exitCode: 1}})
+	if !m.watch.hasLast || m.watch.last.exitCode != 1 {
+		t.Fatalf("last = %+v, hasLast = %v", m.watch.last, m.watch.hasLast)
+	}
+	if got := m.renderWatchStatus(); got == "" {
+		t.Error("renderWatchStatus() is empty while watching")
+	}
+}
+
+func TestUpdate_WatchSelectedTask(t *testing.T) {
+	m := createTestModel(nil, withTasks([]string{"test"}))
+	m.cwd = t.TempDir()
+	m.tasks[0].Source = filepath.Join(m.cwd, "mise.toml")
+	m.tasks[0].Sources = []string{"*.go"}
+	m.filteredTasks = m.tasks
+
+	m, cmd := pressKeys(t, m, "W")
+	if !m.showOutput || !m.watch.active() || m.watch.task != "test" || cmd == nil {
+		t.Fatalf("after W showOutput = %v, watching %q, want test running and watched", m.showOutput, m.watch.task)
+	}
+
+	m, _ = pressKeys(t, m, "W")
+	if m.watch.active() || !m.taskRunning {
+		t.Errorf("after W in the output view watching = %v, running = %v, want the watch stopped and the run kept",
+			m.watch.active(), m.taskRunning)
+	}
+}